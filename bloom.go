@@ -0,0 +1,412 @@
+package rdb
+
+import (
+	"encoding/binary"
+)
+
+const (
+	redisBloomBloomFilterModule  = "MBbloom--"
+	redisBloomCuckooFilterModule = "MBbloomCF"
+
+	redisBloomBloomFilterEncodingVersion  = 4
+	redisBloomCuckooFilterEncodingVersion = 4
+
+	// Options and growth of bloom filters are saved since these versions.
+	redisBloomBloomFilterOptionsVersion = 2
+	redisBloomBloomFilterGrowthVersion  = 4
+
+	// Bucket size, max iterations and expansion of cuckoo filters are saved
+	// since this version.
+	redisBloomCuckooFilterExpansionVersion = 4
+
+	redisBloomBloomFilterDefaultGrowth         = 2
+	redisBloomCuckooFilterDefaultBucketSize    = 2
+	redisBloomCuckooFilterDefaultMaxIterations = 20
+	redisBloomCuckooFilterDefaultExpansion     = 1
+
+	murmurHash2Seed      = 0x9747b28c
+	murmurHash64ASeed    = 0xc6a4a7935bd1e995
+	murmurHash2Multiply  = 0x5bd1e995
+	murmurHash64Multiply = 0xc6a4a7935bd1e995
+)
+
+// Options of bloom filters.
+const (
+	BloomFilterOptionNoRound    = 1
+	BloomFilterOptionEntsIsBits = 2
+	BloomFilterOptionForce64    = 4
+	BloomFilterOptionNoScaling  = 8
+)
+
+// BloomFilter represents a scalable bloom filter implemented by RedisBloom.
+// A new sub-filter is added to Filters when the last one is full.
+type BloomFilter struct {
+	DataKey
+	Size    uint64
+	Options uint64
+	Growth  uint64
+	Filters []BloomSubFilter
+}
+
+// BloomSubFilter is a sub-filter of a bloom filter.
+type BloomSubFilter struct {
+	// Capacity of the sub-filter.
+	Entries      uint64
+	Error        float64
+	Hashes       uint64
+	BitsPerEntry float64
+	Bits         uint64
+	// The number of bits is rounded up to 2^N2 unless N2 is 0.
+	N2   uint64
+	Data []byte
+	// The number of items added to the sub-filter.
+	Size uint64
+}
+
+// Capacity returns the total capacity of sub-filters.
+func (b *BloomFilter) Capacity() uint64 {
+	var capacity uint64
+
+	for _, f := range b.Filters {
+		capacity += f.Entries
+	}
+
+	return capacity
+}
+
+// ErrorRate returns the error rate the bloom filter was created with.
+func (b *BloomFilter) ErrorRate() float64 {
+	if len(b.Filters) == 0 {
+		return 0
+	}
+
+	return b.Filters[0].Error
+}
+
+// Exists returns true if the item may have been added to the bloom filter, in
+// the same way as BF.EXISTS.
+func (b *BloomFilter) Exists(item []byte) bool {
+	force64 := b.Options&BloomFilterOptionForce64 != 0
+
+	var hashA, hashB uint64
+
+	if force64 {
+		hashA = murmurHash64A(item, murmurHash64ASeed)
+		hashB = murmurHash64A(item, hashA)
+	} else {
+		hashA = uint64(murmurHash2(item, murmurHash2Seed))
+		hashB = uint64(murmurHash2(item, uint32(hashA)))
+	}
+
+	for i := len(b.Filters) - 1; i >= 0; i-- {
+		if b.Filters[i].exists(hashA, hashB, force64) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *BloomSubFilter) exists(hashA, hashB uint64, force64 bool) bool {
+	var mod uint64
+
+	switch {
+	case b.N2 == 0:
+		mod = b.Bits
+	case force64 || b.N2 > 31:
+		mod = 1 << b.N2
+	default:
+		mod = uint64(uint32(1) << b.N2)
+	}
+
+	if mod == 0 {
+		return false
+	}
+
+	for i := uint64(0); i < b.Hashes; i++ {
+		x := (hashA + i*hashB) % mod
+		index := x >> 3
+
+		if index >= uint64(len(b.Data)) || b.Data[index]&(1<<(x%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CuckooFilter represents a cuckoo filter implemented by RedisBloom. A new
+// sub-filter is added to Filters when the last one is full.
+type CuckooFilter struct {
+	DataKey
+	NumBuckets    uint64
+	NumItems      uint64
+	NumDeletes    uint64
+	BucketSize    uint64
+	MaxIterations uint64
+	Expansion     uint64
+	Filters       []CuckooSubFilter
+}
+
+// CuckooSubFilter is a sub-filter of a cuckoo filter. Data contains
+// NumBuckets buckets of 1-byte fingerprints.
+type CuckooSubFilter struct {
+	NumBuckets uint64
+	Data       []byte
+}
+
+// Exists returns true if the item may have been added to the cuckoo filter,
+// in the same way as CF.EXISTS.
+func (c *CuckooFilter) Exists(item []byte) bool {
+	hash := murmurHash64A(item, 0)
+	fp := byte(hash%255 + 1)
+	altHash := hash ^ uint64(fp)*murmurHash2Multiply
+
+	for _, f := range c.Filters {
+		if f.find(hash, fp, c.BucketSize) || f.find(altHash, fp, c.BucketSize) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CuckooSubFilter) find(hash uint64, fp byte, bucketSize uint64) bool {
+	if c.NumBuckets == 0 {
+		return false
+	}
+
+	start := (hash % c.NumBuckets) * bucketSize
+	end := start + bucketSize
+
+	if end > uint64(len(c.Data)) {
+		return false
+	}
+
+	for _, v := range c.Data[start:end] {
+		if v == fp {
+			return true
+		}
+	}
+
+	return false
+}
+
+type bloomFilterDecoder struct{}
+
+// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/src/rebloom.c#L1116-L1131
+func (bloomFilterDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion > redisBloomBloomFilterEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisBloomBloomFilterModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	var err error
+
+	bf := &BloomFilter{
+		DataKey: key,
+		Growth:  redisBloomBloomFilterDefaultGrowth,
+	}
+
+	if bf.Size, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	numFilters, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	if encodingVersion >= redisBloomBloomFilterOptionsVersion {
+		if bf.Options, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	if encodingVersion >= redisBloomBloomFilterGrowthVersion {
+		if bf.Growth, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := uint64(0); i < numFilters; i++ {
+		filter, err := readBloomSubFilter(r, encodingVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		bf.Filters = append(bf.Filters, *filter)
+	}
+
+	return bf, nil
+}
+
+func readBloomSubFilter(r *ModuleReader, encodingVersion int) (*BloomSubFilter, error) {
+	var err error
+
+	filter := new(BloomSubFilter)
+
+	if filter.Entries, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if filter.Error, err = r.ReadDouble(); err != nil {
+		return nil, err
+	}
+
+	if filter.Hashes, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if filter.BitsPerEntry, err = r.ReadDouble(); err != nil {
+		return nil, err
+	}
+
+	// Bits and N2 are not saved in the first version
+	if encodingVersion == 0 {
+		filter.Bits = uint64(float64(filter.Entries) * filter.BitsPerEntry)
+	} else {
+		if filter.Bits, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+
+		if filter.N2, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Data = []byte(data)
+
+	if filter.Size, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+type cuckooFilterDecoder struct{}
+
+func (cuckooFilterDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion > redisBloomCuckooFilterEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisBloomCuckooFilterModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	cf := &CuckooFilter{
+		DataKey:       key,
+		BucketSize:    redisBloomCuckooFilterDefaultBucketSize,
+		MaxIterations: redisBloomCuckooFilterDefaultMaxIterations,
+		Expansion:     redisBloomCuckooFilterDefaultExpansion,
+	}
+
+	numFilters, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []*uint64{&cf.NumBuckets, &cf.NumItems, &cf.NumDeletes}
+
+	if encodingVersion >= redisBloomCuckooFilterExpansionVersion {
+		fields = append(fields, &cf.BucketSize, &cf.MaxIterations, &cf.Expansion)
+	}
+
+	for _, field := range fields {
+		if *field, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := uint64(0); i < numFilters; i++ {
+		filter := CuckooSubFilter{NumBuckets: cf.NumBuckets}
+
+		if encodingVersion >= redisBloomCuckooFilterExpansionVersion {
+			if filter.NumBuckets, err = r.ReadUnsigned(); err != nil {
+				return nil, err
+			}
+		}
+
+		data, err := r.ReadString()
+		if err != nil {
+			return nil, err
+		}
+
+		filter.Data = []byte(data)
+		cf.Filters = append(cf.Filters, filter)
+	}
+
+	return cf, nil
+}
+
+// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/contrib/MurmurHash2.c
+func murmurHash2(data []byte, seed uint32) uint32 {
+	const r = 24
+
+	h := seed ^ uint32(len(data))
+
+	for ; len(data) >= 4; data = data[4:] {
+		k := binary.LittleEndian.Uint32(data)
+		k *= murmurHash2Multiply
+		k ^= k >> r
+		k *= murmurHash2Multiply
+		h *= murmurHash2Multiply
+		h ^= k
+	}
+
+	switch len(data) {
+	case 3:
+		h ^= uint32(data[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[0])
+		h *= murmurHash2Multiply
+	}
+
+	h ^= h >> 13
+	h *= murmurHash2Multiply
+	h ^= h >> 15
+
+	return h
+}
+
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const r = 47
+
+	h := seed ^ uint64(len(data))*murmurHash64Multiply
+
+	for ; len(data) >= 8; data = data[8:] {
+		k := binary.LittleEndian.Uint64(data)
+		k *= murmurHash64Multiply
+		k ^= k >> r
+		k *= murmurHash64Multiply
+		h ^= k
+		h *= murmurHash64Multiply
+	}
+
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * uint(i))
+		}
+
+		h *= murmurHash64Multiply
+	}
+
+	h ^= h >> r
+	h *= murmurHash64Multiply
+	h ^= h >> r
+
+	return h
+}
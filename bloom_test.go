@@ -0,0 +1,173 @@
+package rdb
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RedisBloom", func() {
+	readFirstData := func(name string) interface{} {
+		file, err := os.Open("fixtures/" + name + ".rdb")
+		Expect(err).NotTo(HaveOccurred())
+
+		defer file.Close()
+
+		parser := NewParser(file)
+
+		for {
+			data, err := parser.Next()
+			Expect(errors.Is(err, io.EOF)).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			switch data.(type) {
+			case *BloomFilter, *CuckooFilter, *TopK, *TDigest, *CountMinSketch:
+				return data
+			}
+		}
+	}
+
+	Describe("BloomFilter", func() {
+		var bf *BloomFilter
+
+		BeforeEach(func() {
+			bf = readFirstData("bloom_filter").(*BloomFilter)
+		})
+
+		DescribeTable("Exists", func(item string, expected bool) {
+			Expect(bf.Exists([]byte(item))).To(Equal(expected))
+		},
+			Entry("added item", "foo", true),
+			Entry("other item", "bar", false),
+			Entry("empty item", "", false),
+		)
+
+		It("should return the capacity", func() {
+			Expect(bf.Capacity()).To(Equal(uint64(100)))
+		})
+
+		It("should return the error rate", func() {
+			Expect(bf.ErrorRate()).To(Equal(0.005))
+		})
+	})
+
+	Describe("CuckooFilter", func() {
+		var cf *CuckooFilter
+
+		BeforeEach(func() {
+			cf = readFirstData("cuckoo_filter").(*CuckooFilter)
+		})
+
+		DescribeTable("Exists", func(item string, expected bool) {
+			Expect(cf.Exists([]byte(item))).To(Equal(expected))
+		},
+			Entry("added item", "foo", true),
+			Entry("other item", "bar", false),
+			Entry("empty item", "", false),
+		)
+
+		It("should return UnsupportedDataTypeError for unknown encoding versions", func() {
+			_, err := topKDecoder{}.DecodeModule(nil, DataKey{}, 1)
+			Expect(err).To(Equal(UnsupportedDataTypeError{
+				DataType:        typeModule2,
+				ModuleName:      redisBloomTopKModule,
+				EncodingVersion: 1,
+			}))
+		})
+	})
+	Describe("TopK", func() {
+		var topK *TopK
+
+		BeforeEach(func() {
+			topK = readFirstData("top_k").(*TopK)
+		})
+
+		It("should sort items by count", func() {
+			Expect(topK.Items).To(HaveLen(2))
+			Expect(topK.Items[0].Item).To(Equal("foo"))
+			Expect(topK.Items[1].Item).To(Equal("bar"))
+		})
+
+		DescribeTable("Query", func(item string, expected bool) {
+			Expect(topK.Query([]byte(item))).To(Equal(expected))
+		},
+			Entry("heavy hitter", "foo", true),
+			Entry("other item", "baz", false),
+			Entry("empty item", "", false),
+		)
+
+		It("should return UnsupportedDataTypeError for unknown encoding versions", func() {
+			_, err := topKDecoder{}.DecodeModule(nil, DataKey{}, 1)
+			Expect(err).To(Equal(UnsupportedDataTypeError{
+				DataType:        typeModule2,
+				ModuleName:      redisBloomTopKModule,
+				EncodingVersion: 1,
+			}))
+		})
+	})
+
+	Describe("TDigest", func() {
+		var td *TDigest
+
+		BeforeEach(func() {
+			td = readFirstData("t_digest").(*TDigest)
+		})
+
+		DescribeTable("Quantile", func(q, expected float64) {
+			Expect(td.Quantile(q)).To(BeNumerically("~", expected, 1e-9))
+		},
+			Entry("0", 0.0, 1.0),
+			Entry("0.25", 0.25, 2.0),
+			Entry("0.5", 0.5, 14.0/3),
+			Entry("1", 1.0, 10.0),
+		)
+
+		It("should return NaN when q is out of range", func() {
+			Expect(math.IsNaN(td.Quantile(-0.1))).To(BeTrue())
+			Expect(math.IsNaN(td.Quantile(1.1))).To(BeTrue())
+		})
+
+		It("should return NaN when the sketch is empty", func() {
+			Expect(math.IsNaN((&TDigest{}).Quantile(0.5))).To(BeTrue())
+		})
+
+		It("should return UnsupportedDataTypeError for unknown encoding versions", func() {
+			_, err := tDigestDecoder{}.DecodeModule(nil, DataKey{}, 1)
+			Expect(err).To(Equal(UnsupportedDataTypeError{
+				DataType:        typeModule2,
+				ModuleName:      redisBloomTDigestModule,
+				EncodingVersion: 1,
+			}))
+		})
+	})
+
+	Describe("CountMinSketch", func() {
+		var cms *CountMinSketch
+
+		BeforeEach(func() {
+			cms = readFirstData("count_min_sketch").(*CountMinSketch)
+		})
+
+		DescribeTable("Query", func(item string, expected uint32) {
+			Expect(cms.Query([]byte(item))).To(Equal(expected))
+		},
+			Entry("foo", "foo", uint32(3)),
+			Entry("bar", "bar", uint32(1)),
+			Entry("other item", "baz", uint32(0)),
+		)
+
+		It("should return UnsupportedDataTypeError for unknown encoding versions", func() {
+			_, err := countMinSketchDecoder{}.DecodeModule(nil, DataKey{}, 1)
+			Expect(err).To(Equal(UnsupportedDataTypeError{
+				DataType:        typeModule2,
+				ModuleName:      redisBloomCountMinSketchModule,
+				EncodingVersion: 1,
+			}))
+		})
+	})
+})
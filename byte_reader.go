@@ -54,6 +54,9 @@ type bufferReader struct {
 	length int
 	buf    []byte
 
+	// position is the number of bytes read from r.
+	position int64
+
 	decBuff []byte
 }
 
@@ -65,7 +68,12 @@ func newBufferReader(r io.Reader) *bufferReader {
 
 func (b *bufferReader) ReadBytes(n int) ([]byte, error) {
 	if n > maxBufferSize {
-		return b.readIntoNewBuffer(n)
+		buf, err := b.readIntoNewBuffer(n)
+		if err == nil {
+			b.position += int64(n)
+		}
+
+		return buf, err
 	}
 
 	if b.remaining() < n {
@@ -76,10 +84,16 @@ func (b *bufferReader) ReadBytes(n int) ([]byte, error) {
 
 	offset := b.offset
 	b.offset += n
+	b.position += int64(n)
 
 	return b.buf[offset : offset+n], nil
 }
 
+// Position returns the number of bytes read.
+func (b *bufferReader) Position() int64 {
+	return b.position
+}
+
 func (b *bufferReader) remaining() int {
 	return b.length - b.offset
 }
@@ -167,3 +181,24 @@ func max(a, b int) int {
 
 	return b
 }
+
+// recordingReader keeps a copy of all bytes read from the underlying reader.
+type recordingReader struct {
+	reader byteReader
+	buf    []byte
+}
+
+func (r *recordingReader) ReadBytes(n int) ([]byte, error) {
+	buf, err := r.reader.ReadBytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	r.buf = append(r.buf, buf...)
+
+	return buf, nil
+}
+
+func (r *recordingReader) MakeByteSlice(n int) []byte {
+	return r.reader.MakeByteSlice(n)
+}
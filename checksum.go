@@ -0,0 +1,38 @@
+package rdb
+
+import "hash/crc64"
+
+// crc64Jones is the reflected form of the Jones polynomial used by Redis.
+const crc64Jones = 0x95ac9329ac4bc9b5
+
+// nolint: gochecknoglobals
+var crc64Table = crc64.MakeTable(crc64Jones)
+
+// updateCRC64 returns the result of adding the bytes in p to crc. Redis does not
+// invert the CRC before and after the update like hash/crc64 does, so we have to
+// revert it.
+func updateCRC64(crc uint64, p []byte) uint64 {
+	return ^crc64.Update(^crc, crc64Table, p)
+}
+
+// checksumReader calculates the CRC64 checksum of all bytes read from the
+// underlying reader.
+type checksumReader struct {
+	reader   byteReader
+	checksum uint64
+}
+
+func (c *checksumReader) ReadBytes(n int) ([]byte, error) {
+	buf, err := c.reader.ReadBytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	c.checksum = updateCRC64(c.checksum, buf)
+
+	return buf, nil
+}
+
+func (c *checksumReader) MakeByteSlice(n int) []byte {
+	return c.reader.MakeByteSlice(n)
+}
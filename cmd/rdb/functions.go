@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tommy351/rdb-go"
+)
+
+// nolint: gochecknoglobals
+var (
+	functionsDir string
+
+	functionsCmd = &cobra.Command{
+		Use:   "functions [path]",
+		Short: "Extract Redis function libraries",
+		Args:  cobra.MaximumNArgs(1),
+		Example: formatExamples([][]string{
+			{"Extract function libraries to the current directory.", "rdb functions path/to/dump.rdb"},
+			{"Extract function libraries to a directory.", "rdb functions -d path/to/dir path/to/dump.rdb"},
+		}),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reader, closeReader, err := openInput(args)
+			if err != nil {
+				return err
+			}
+
+			defer closeReader()
+
+			return extractFunctions(reader, functionsDir)
+		},
+	}
+)
+
+// extractFunctions writes each function library to a file named after the
+// library and the engine, e.g. "mylib.lua".
+func extractFunctions(reader io.Reader, dir string) error {
+	parser := rdb.NewParser(reader)
+
+	// Skip values because only function libraries are extracted
+	parser.KeyFilter = func(key *rdb.DataKey) bool {
+		return false
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for {
+		data, err := parser.Next()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("parser error: %w", err)
+		}
+
+		lib, ok := data.(*rdb.FunctionLibrary)
+		if !ok {
+			continue
+		}
+
+		if err := writeFunctionLibrary(dir, lib); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFunctionLibrary(dir string, lib *rdb.FunctionLibrary) error {
+	name := lib.Name + "." + strings.ToLower(lib.Engine)
+
+	// Library names can't contain path separators in Redis
+	if filepath.Base(name) != name {
+		// nolint: goerr113
+		return fmt.Errorf("invalid function library name %q", lib.Name)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(lib.Code), 0o644); err != nil {
+		return fmt.Errorf("failed to write function library: %w", err)
+	}
+
+	return nil
+}
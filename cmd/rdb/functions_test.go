@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("extractFunctions", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "rdb-functions")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	extract := func(name string) {
+		file, err := os.Open("../../fixtures/" + name + ".rdb")
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+		Expect(extractFunctions(file, dir)).To(Succeed())
+	}
+
+	readDir := func() map[string]string {
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		result := map[string]string{}
+
+		for _, f := range files {
+			content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+			Expect(err).NotTo(HaveOccurred())
+			result[f.Name()] = string(content)
+		}
+
+		return result
+	}
+
+	It("should write libraries to lua files", func() {
+		extract("redis_70_with_functions")
+		Expect(readDir()).To(Equal(map[string]string{
+			"mylib.lua":    "#!lua name=mylib\nredis.register_function('knockknock', function() return 'Who\\'s there?' end)\n",
+			"counters.lua": "#!lua name=counters\n\nredis.register_function('incr_by_two', function(keys) return redis.call('INCRBY', keys[1], 2) end)\n",
+		}))
+	})
+
+	It("should write functions of release candidates to lua files", func() {
+		extract("redis_70_with_functions_pre_ga")
+		Expect(readDir()).To(Equal(map[string]string{
+			"knockknock.lua": "return 'Who\\'s there?'",
+			"hello.lua":      "return 'hello'",
+		}))
+	})
+
+	It("should not write files when there are no functions", func() {
+		extract("keys_with_expiry")
+		Expect(readDir()).To(BeEmpty())
+	})
+})
@@ -213,3 +213,19 @@ func (j *JSONPrinter) HashEntry(entry *rdb.HashEntry) error {
 func (j *JSONPrinter) HashData(data *rdb.HashData) error {
 	return j.printObjectEnd()
 }
+
+func (j *JSONPrinter) JSON(data *rdb.JSONData) error {
+	if err := j.printKey(&data.DataKey); err != nil {
+		return err
+	}
+
+	return j.printValue(data.Value)
+}
+
+func (j *JSONPrinter) StreamData(data *rdb.StreamData) error {
+	return nil
+}
+
+func (j *JSONPrinter) Module(key *rdb.DataKey, data interface{}) error {
+	return nil
+}
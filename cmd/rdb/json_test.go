@@ -33,7 +33,11 @@ var _ = Describe("JSONPrinter", func() {
 
 		It("should match the golden file", func() {
 			var data []interface{}
-			Expect(json.NewDecoder(&buf).Decode(&data)).To(Succeed())
+
+			// Keep numbers as printed, which may not fit in float64
+			decoder := json.NewDecoder(&buf)
+			decoder.UseNumber()
+			Expect(decoder.Decode(&data)).To(Succeed())
 			Expect(data).To(matchGoldenFile())
 		})
 	}
@@ -53,6 +57,11 @@ var _ = Describe("JSONPrinter", func() {
 		"sorted_set_as_ziplist",
 		// Hash
 		"hash_as_ziplist",
+		// Listpack
+		"redis_70_with_listpacks",
+		// RedisJSON
+		"redis_40_with_module",
+		"redis_json",
 	} {
 		name := name
 		Describe(name, func() {
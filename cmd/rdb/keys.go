@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tommy351/rdb-go"
+)
+
+// KeysPrinter prints a CSV row for each key with its expiry, LRU idle time in
+// seconds and LFU frequency. Columns are left empty when they are not saved.
+type KeysPrinter struct {
+	writer *csv.Writer
+}
+
+func NewKeysPrinter(w io.Writer) *KeysPrinter {
+	return &KeysPrinter{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (k *KeysPrinter) write(record []string) error {
+	if err := k.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to print csv: %w", err)
+	}
+
+	return nil
+}
+
+func (k *KeysPrinter) printKey(key *rdb.DataKey) error {
+	var expiry, idle, freq string
+
+	if key.Expiry != nil {
+		expiry = key.Expiry.UTC().Format(time.RFC3339Nano)
+	}
+
+	if key.Idle != nil {
+		idle = strconv.FormatInt(int64(*key.Idle/time.Second), 10)
+	}
+
+	if key.Freq != nil {
+		freq = strconv.FormatUint(uint64(*key.Freq), 10)
+	}
+
+	return k.write([]string{strconv.Itoa(key.Database), key.Key, expiry, idle, freq})
+}
+
+func (k *KeysPrinter) Start() error {
+	return k.write([]string{"database", "key", "expiry", "idle", "freq"})
+}
+
+func (k *KeysPrinter) End() error {
+	k.writer.Flush()
+
+	if err := k.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+
+	return nil
+}
+
+func (k *KeysPrinter) String(data *rdb.StringData) error {
+	return k.printKey(&data.DataKey)
+}
+
+func (k *KeysPrinter) ListHead(head *rdb.ListHead) error {
+	return k.printKey(&head.DataKey)
+}
+
+func (k *KeysPrinter) ListEntry(entry *rdb.ListEntry) error {
+	return nil
+}
+
+func (k *KeysPrinter) ListData(data *rdb.ListData) error {
+	return nil
+}
+
+func (k *KeysPrinter) SetHead(head *rdb.SetHead) error {
+	return k.printKey(&head.DataKey)
+}
+
+func (k *KeysPrinter) SetEntry(entry *rdb.SetEntry) error {
+	return nil
+}
+
+func (k *KeysPrinter) SetData(data *rdb.SetData) error {
+	return nil
+}
+
+func (k *KeysPrinter) SortedSetHead(head *rdb.SortedSetHead) error {
+	return k.printKey(&head.DataKey)
+}
+
+func (k *KeysPrinter) SortedSetEntry(entry *rdb.SortedSetEntry) error {
+	return nil
+}
+
+func (k *KeysPrinter) SortedSetData(data *rdb.SortedSetData) error {
+	return nil
+}
+
+func (k *KeysPrinter) HashHead(head *rdb.HashHead) error {
+	return k.printKey(&head.DataKey)
+}
+
+func (k *KeysPrinter) HashEntry(entry *rdb.HashEntry) error {
+	return nil
+}
+
+func (k *KeysPrinter) HashData(data *rdb.HashData) error {
+	return nil
+}
+
+func (k *KeysPrinter) JSON(data *rdb.JSONData) error {
+	return k.printKey(&data.DataKey)
+}
+
+func (k *KeysPrinter) StreamData(data *rdb.StreamData) error {
+	return k.printKey(&data.DataKey)
+}
+
+func (k *KeysPrinter) Module(key *rdb.DataKey, data interface{}) error {
+	return k.printKey(key)
+}
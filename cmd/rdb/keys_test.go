@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tommy351/goldga"
+)
+
+var _ = Describe("KeysPrinter", func() {
+	matchGoldenFile := func() *goldga.Matcher {
+		matcher := goldga.Match()
+		matcher.Serializer = &goldga.JSONSerializer{}
+
+		return matcher
+	}
+
+	testDumpFile := func(name string) {
+		var buf bytes.Buffer
+
+		BeforeEach(func() {
+			buf.Reset()
+			printer := NewKeysPrinter(&buf)
+			file, err := os.Open(fmt.Sprintf("../../fixtures/%s.rdb", name))
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+			Expect(printParserData(file, printer)).To(Succeed())
+		})
+
+		It("should match the golden file", func() {
+			records, err := csv.NewReader(&buf).ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(matchGoldenFile())
+		})
+	}
+
+	for _, name := range []string{
+		"keys_with_expiry",
+		"multiple_databases",
+		"redis_62_with_lru_idle",
+		"redis_62_with_lfu_freq",
+		"redis_json",
+		"redis_50_with_streams",
+		"redis_40_with_module",
+		"bloom_filter",
+		"redis_time_series",
+	} {
+		name := name
+		Describe(name, func() {
+			testDumpFile(name)
+		})
+	}
+})
@@ -22,6 +22,9 @@ var (
 		Example: formatExamples([][]string{
 			{"Parse a RDB dump file.", "rdb path/to/dump.rdb"},
 			{"Read RDB from stdin.", "cat file | rdb"},
+			{"List keys with expiry, LRU idle time and LFU frequency.", "rdb -o keys path/to/dump.rdb"},
+			{"Replay keys to another Redis.", "rdb -o resp path/to/dump.rdb | redis-cli --pipe"},
+			{"Restore keys to another Redis.", "rdb -o restore path/to/dump.rdb | redis-cli --pipe"},
 		}),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -30,34 +33,48 @@ var (
 			writer := bufio.NewWriter(os.Stdout)
 			defer writer.Flush()
 
+			reader, closeReader, err := openInput(args)
+			if err != nil {
+				return err
+			}
+
+			defer closeReader()
+
 			switch outputFormat {
 			case "json":
 				printer = NewJSONPrinter(writer)
+			case "keys":
+				printer = NewKeysPrinter(writer)
+			case "resp":
+				printer = NewRESPPrinter(writer)
+			case "restore":
+				// RESTORE commands are printed from raw values, which are not
+				// decoded for printers.
+				return printRestoreCommands(reader, writer)
 			default:
 				// nolint: goerr113
 				return fmt.Errorf("unsupported format %q", outputFormat)
 			}
 
-			var reader io.Reader
-
-			if len(args) > 0 {
-				file, err := os.Open(args[0])
-				if err != nil {
-					return fmt.Errorf("failed to open file: %w", err)
-				}
-
-				defer file.Close()
-
-				reader = file
-			} else {
-				reader = bufio.NewReader(os.Stdin)
-			}
-
 			return printParserData(reader, printer)
 		},
 	}
 )
 
+// openInput opens the file in args, or stdin if args is empty.
+func openInput(args []string) (io.Reader, func(), error) {
+	if len(args) == 0 {
+		return bufio.NewReader(os.Stdin), func() {}, nil
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return file, func() { file.Close() }, nil
+}
+
 func formatExamples(examples [][]string) string {
 	lines := make([]string, len(examples))
 	indent := "  "
@@ -70,7 +87,10 @@ func formatExamples(examples [][]string) string {
 }
 
 func main() {
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "json", "output format")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "json", "output format (json, keys, resp, restore)")
+	functionsCmd.Flags().StringVarP(&functionsDir, "dir", "d", ".", "output directory")
+	rootCmd.AddCommand(functionsCmd)
+	rootCmd.AddCommand(memoryCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -122,6 +142,14 @@ func printParserData(reader io.Reader, printer Printer) error {
 			err = printer.HashEntry(v)
 		case *rdb.HashData:
 			err = printer.HashData(v)
+		case *rdb.StreamData:
+			err = printer.StreamData(v)
+		case *rdb.JSONData:
+			err = printer.JSON(v)
+		default:
+			if key := moduleDataKey(data); key != nil {
+				err = printer.Module(key, data)
+			}
 		}
 
 		if err != nil {
@@ -131,3 +159,26 @@ func printParserData(reader io.Reader, printer Printer) error {
 
 	return printer.End()
 }
+
+// moduleDataKey returns the key of module values, or nil if data is not a
+// module value.
+func moduleDataKey(data interface{}) *rdb.DataKey {
+	switch v := data.(type) {
+	case *rdb.BloomFilter:
+		return &v.DataKey
+	case *rdb.CuckooFilter:
+		return &v.DataKey
+	case *rdb.TopK:
+		return &v.DataKey
+	case *rdb.TDigest:
+		return &v.DataKey
+	case *rdb.CountMinSketch:
+		return &v.DataKey
+	case *rdb.TimeSeriesData:
+		return &v.DataKey
+	case *rdb.ModuleData:
+		return &v.DataKey
+	}
+
+	return nil
+}
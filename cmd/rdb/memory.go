@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tommy351/rdb-go"
+)
+
+// nolint: gochecknoglobals
+var memoryCmd = &cobra.Command{
+	Use:   "memory [path]",
+	Short: "Report estimated memory usage of keys",
+	Args:  cobra.MaximumNArgs(1),
+	Example: formatExamples([][]string{
+		{"Report memory usage of keys in CSV.", "rdb memory path/to/dump.rdb"},
+	}),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		writer := bufio.NewWriter(os.Stdout)
+		defer writer.Flush()
+
+		reader, closeReader, err := openInput(args)
+		if err != nil {
+			return err
+		}
+
+		defer closeReader()
+
+		return printMemoryReport(reader, writer)
+	},
+}
+
+// printMemoryReport prints a CSV row with the estimated memory usage of each
+// key.
+func printMemoryReport(reader io.Reader, w io.Writer) error {
+	parser := rdb.NewParser(reader)
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"database", "type", "key", "size_in_bytes", "encoding", "num_elements", "len_largest_element", "expiry",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to print csv: %w", err)
+	}
+
+	for {
+		data, err := parser.Next()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("parser error: %w", err)
+		}
+
+		usage := rdb.EstimateMemory(data)
+		if usage == nil {
+			continue
+		}
+
+		var size, expiry string
+
+		// The size is left empty when the value can't be estimated
+		if !usage.ValueUnknown {
+			size = strconv.FormatInt(usage.Size, 10)
+		}
+
+		if usage.Expiry != nil {
+			expiry = usage.Expiry.UTC().Format(time.RFC3339Nano)
+		}
+
+		record := []string{
+			strconv.Itoa(usage.Database),
+			usage.Type,
+			usage.Key,
+			size,
+			usage.Encoding,
+			strconv.Itoa(usage.NumElements),
+			strconv.Itoa(usage.LenLargestElement),
+			expiry,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to print csv: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+
+	return nil
+}
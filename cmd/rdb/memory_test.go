@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tommy351/goldga"
+)
+
+var _ = Describe("printMemoryReport", func() {
+	matchGoldenFile := func() *goldga.Matcher {
+		matcher := goldga.Match()
+		matcher.Serializer = &goldga.JSONSerializer{}
+
+		return matcher
+	}
+
+	for _, name := range []string{
+		"keys_with_expiry",
+		"multiple_databases",
+		"intset_16",
+		"regular_set",
+		"regular_sorted_set",
+		"sorted_set_as_ziplist",
+		"hash_as_ziplist",
+		"quicklist",
+		"redis_50_with_streams",
+		"redis_72_with_listpacks",
+		"redis_json",
+		"bloom_filter",
+		"redis_time_series",
+	} {
+		name := name
+
+		Describe(name, func() {
+			It("should match the golden file", func() {
+				var buf bytes.Buffer
+
+				file, err := os.Open(fmt.Sprintf("../../fixtures/%s.rdb", name))
+				Expect(err).NotTo(HaveOccurred())
+				defer file.Close()
+				Expect(printMemoryReport(file, &buf)).To(Succeed())
+
+				records, err := csv.NewReader(&buf).ReadAll()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(records).To(matchGoldenFile())
+			})
+		})
+	}
+})
@@ -23,4 +23,10 @@ type Printer interface {
 	HashHead(head *rdb.HashHead) error
 	HashEntry(head *rdb.HashEntry) error
 	HashData(data *rdb.HashData) error
+
+	StreamData(data *rdb.StreamData) error
+
+	JSON(data *rdb.JSONData) error
+	// Module is called with values of modules other than RedisJSON.
+	Module(key *rdb.DataKey, data interface{}) error
 }
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tommy351/rdb-go"
+)
+
+// writeCommand writes a command as a RESP array of bulk strings.
+func writeCommand(w io.Writer, args ...string) error {
+	buf := make([]byte, 0, 16*len(args))
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write command: %w", err)
+	}
+
+	return nil
+}
+
+// respChunkSize is the maximum number of arguments added to a command of a
+// collection. Bigger collections are split into multiple commands.
+const respChunkSize = 512
+
+// RESPPrinter prints commands in RESP which recreate keys, which can be
+// replayed with "redis-cli --pipe". SELECT is printed when the database is
+// changed, and PEXPIREAT is printed after keys with expiry. Values of modules
+// other than RedisJSON can't be printed and an error is returned.
+type RESPPrinter struct {
+	writer io.Writer
+	db     int
+	args   []string
+}
+
+func NewRESPPrinter(w io.Writer) *RESPPrinter {
+	return &RESPPrinter{
+		writer: w,
+	}
+}
+
+func (r *RESPPrinter) selectDB(key *rdb.DataKey) error {
+	if key.Database == r.db {
+		return nil
+	}
+
+	r.db = key.Database
+
+	return writeCommand(r.writer, "SELECT", strconv.Itoa(key.Database))
+}
+
+// begin selects the database of the key and starts a command of a collection.
+func (r *RESPPrinter) begin(key *rdb.DataKey, command string) error {
+	if err := r.selectDB(key); err != nil {
+		return err
+	}
+
+	r.args = append(r.args[:0], command, key.Key)
+
+	return nil
+}
+
+func (r *RESPPrinter) add(args ...string) error {
+	r.args = append(r.args, args...)
+
+	if len(r.args)-2 < respChunkSize {
+		return nil
+	}
+
+	return r.flush()
+}
+
+func (r *RESPPrinter) flush() error {
+	if len(r.args) <= 2 {
+		return nil
+	}
+
+	if err := writeCommand(r.writer, r.args...); err != nil {
+		return err
+	}
+
+	r.args = r.args[:2]
+
+	return nil
+}
+
+// end flushes the command of a collection and prints the expiry of the key.
+func (r *RESPPrinter) end(key *rdb.DataKey) error {
+	if err := r.flush(); err != nil {
+		return err
+	}
+
+	return r.expire(key)
+}
+
+func (r *RESPPrinter) expire(key *rdb.DataKey) error {
+	if key.Expiry == nil {
+		return nil
+	}
+
+	return writeCommand(r.writer, "PEXPIREAT", key.Key, formatMilliseconds(*key.Expiry))
+}
+
+func (r *RESPPrinter) Start() error {
+	return nil
+}
+
+func (r *RESPPrinter) End() error {
+	return nil
+}
+
+func (r *RESPPrinter) String(data *rdb.StringData) error {
+	if err := r.selectDB(&data.DataKey); err != nil {
+		return err
+	}
+
+	if err := writeCommand(r.writer, "SET", data.Key, data.Value); err != nil {
+		return err
+	}
+
+	return r.expire(&data.DataKey)
+}
+
+func (r *RESPPrinter) ListHead(head *rdb.ListHead) error {
+	return r.begin(&head.DataKey, "RPUSH")
+}
+
+func (r *RESPPrinter) ListEntry(entry *rdb.ListEntry) error {
+	return r.add(entry.Value)
+}
+
+func (r *RESPPrinter) ListData(data *rdb.ListData) error {
+	return r.end(&data.DataKey)
+}
+
+func (r *RESPPrinter) SetHead(head *rdb.SetHead) error {
+	return r.begin(&head.DataKey, "SADD")
+}
+
+func (r *RESPPrinter) SetEntry(entry *rdb.SetEntry) error {
+	return r.add(entry.Value)
+}
+
+func (r *RESPPrinter) SetData(data *rdb.SetData) error {
+	return r.end(&data.DataKey)
+}
+
+func (r *RESPPrinter) SortedSetHead(head *rdb.SortedSetHead) error {
+	return r.begin(&head.DataKey, "ZADD")
+}
+
+func (r *RESPPrinter) SortedSetEntry(entry *rdb.SortedSetEntry) error {
+	return r.add(formatScore(entry.Score), entry.Value)
+}
+
+func (r *RESPPrinter) SortedSetData(data *rdb.SortedSetData) error {
+	return r.end(&data.DataKey)
+}
+
+func (r *RESPPrinter) HashHead(head *rdb.HashHead) error {
+	return r.begin(&head.DataKey, "HSET")
+}
+
+func (r *RESPPrinter) HashEntry(entry *rdb.HashEntry) error {
+	return r.add(entry.Index, entry.Value)
+}
+
+func (r *RESPPrinter) HashData(data *rdb.HashData) error {
+	if err := r.flush(); err != nil {
+		return err
+	}
+
+	fields := make([]string, 0, len(data.FieldExpiry))
+
+	for field := range data.FieldExpiry {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	// Fields with TTL are supported since Redis 7.4
+	for _, field := range fields {
+		expiry := formatMilliseconds(data.FieldExpiry[field])
+
+		if err := writeCommand(r.writer, "HPEXPIREAT", data.Key, expiry, "FIELDS", "1", field); err != nil {
+			return err
+		}
+	}
+
+	return r.expire(&data.DataKey)
+}
+
+func (r *RESPPrinter) JSON(data *rdb.JSONData) error {
+	if err := r.selectDB(&data.DataKey); err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(data.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %w", err)
+	}
+
+	if err := writeCommand(r.writer, "JSON.SET", data.Key, "$", string(buf)); err != nil {
+		return err
+	}
+
+	return r.expire(&data.DataKey)
+}
+
+func formatMilliseconds(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// formatScore formats a score of a sorted set in the format accepted by ZADD.
+// The shortest representation is used, which is parsed to the same value.
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+// StreamData prints commands in the same way as AOF rewrite of Redis. Pending
+// entries are claimed by their consumers, and consumers without pending entries
+// are created by XGROUP CREATECONSUMER, which is supported since Redis 6.2.
+func (r *RESPPrinter) StreamData(data *rdb.StreamData) error {
+	if err := r.selectDB(&data.DataKey); err != nil {
+		return err
+	}
+
+	// Empty streams whose last ID is 0-0 can't be created by XADD, so they are
+	// created along with a consumer group.
+	mkStream := len(data.Value) == 0 && data.LastID == rdb.StreamID{}
+
+	if mkStream {
+		if err := r.createEmptyStream(data); err != nil {
+			return err
+		}
+	} else if err := r.streamEntries(data); err != nil {
+		return err
+	}
+
+	for i, group := range data.Groups {
+		if err := r.streamGroup(data, &group, mkStream && i == 0); err != nil {
+			return err
+		}
+	}
+
+	return r.expire(&data.DataKey)
+}
+
+func (r *RESPPrinter) createEmptyStream(data *rdb.StreamData) error {
+	if len(data.Groups) > 0 {
+		return nil
+	}
+
+	if err := writeCommand(r.writer, "XGROUP", "CREATE", data.Key, "rdb", "0", "MKSTREAM"); err != nil {
+		return err
+	}
+
+	return writeCommand(r.writer, "XGROUP", "DESTROY", data.Key, "rdb")
+}
+
+func (r *RESPPrinter) streamEntries(data *rdb.StreamData) error {
+	for _, value := range data.Value {
+		args := make([]string, 0, 3+len(value.Fields)*2)
+		args = append(args, "XADD", data.Key, value.ID.String())
+
+		for _, field := range value.Fields {
+			args = append(args, field.Field, field.Value)
+		}
+
+		if err := writeCommand(r.writer, args...); err != nil {
+			return err
+		}
+	}
+
+	// Empty streams are created by an entry which is trimmed immediately
+	if len(data.Value) == 0 {
+		if err := writeCommand(r.writer, "XADD", data.Key, "MAXLEN", "0", data.LastID.String(), "x", "y"); err != nil {
+			return err
+		}
+	}
+
+	return r.streamID(data)
+}
+
+func (r *RESPPrinter) streamID(data *rdb.StreamData) error {
+	args := []string{"XSETID", data.Key, data.LastID.String()}
+
+	// The number of added entries is saved since Redis 7.0
+	if data.EntriesAdded > 0 {
+		args = append(args,
+			"ENTRIESADDED", strconv.Itoa(data.EntriesAdded),
+			"MAXDELETEDID", data.MaxDeletedID.String())
+	}
+
+	return writeCommand(r.writer, args...)
+}
+
+func (r *RESPPrinter) streamGroup(data *rdb.StreamData, group *rdb.StreamGroup, mkStream bool) error {
+	args := []string{"XGROUP", "CREATE", data.Key, group.Name, group.LastDeliveredID.String()}
+
+	if mkStream {
+		args = append(args, "MKSTREAM")
+	}
+
+	if data.EntriesAdded > 0 {
+		args = append(args, "ENTRIESREAD", strconv.Itoa(group.EntriesRead))
+	}
+
+	if err := writeCommand(r.writer, args...); err != nil {
+		return err
+	}
+
+	for _, consumer := range group.Consumers {
+		if len(consumer.Pending) == 0 {
+			if err := writeCommand(r.writer, "XGROUP", "CREATECONSUMER", data.Key, group.Name, consumer.Name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, entry := range consumer.Pending {
+			err := writeCommand(r.writer, "XCLAIM", data.Key, group.Name, consumer.Name, "0", entry.ID.String(),
+				"TIME", formatMilliseconds(entry.DeliveryTime),
+				"RETRYCOUNT", strconv.Itoa(entry.DeliveryCount),
+				"JUSTID", "FORCE")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Module returns an error because values of modules can't be recreated with
+// commands in general. They can be replayed with the restore output format.
+func (r *RESPPrinter) Module(key *rdb.DataKey, data interface{}) error {
+	return fmt.Errorf("%T of key %q can not be printed as resp, use the restore output format instead", data, key.Key)
+}
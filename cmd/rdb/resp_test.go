@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tommy351/goldga"
+	"github.com/tommy351/rdb-go"
+)
+
+var _ = Describe("RESPPrinter", func() {
+	matchGoldenFile := func() *goldga.Matcher {
+		matcher := goldga.Match()
+		matcher.Serializer = &goldga.JSONSerializer{}
+
+		return matcher
+	}
+
+	testDumpFile := func(name string) {
+		var buf bytes.Buffer
+
+		BeforeEach(func() {
+			buf.Reset()
+			printer := NewRESPPrinter(&buf)
+			file, err := os.Open(fmt.Sprintf("../../fixtures/%s.rdb", name))
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+			Expect(printParserData(file, printer)).To(Succeed())
+		})
+
+		It("should match the golden file", func() {
+			Expect(readCommands(&buf)).To(matchGoldenFile())
+		})
+	}
+
+	for _, name := range []string{
+		"keys_with_expiry",
+		"multiple_databases",
+		"linkedlist",
+		"regular_set",
+		"regular_sorted_set",
+		"hash_as_ziplist",
+		"redis_74_with_hash_field_expiry",
+		"redis_json",
+		"redis_50_with_streams",
+		"redis_70_with_listpacks",
+	} {
+		name := name
+		Describe(name, func() {
+			testDumpFile(name)
+		})
+	}
+
+	It("should create empty streams without IDs with a consumer group", func() {
+		var buf bytes.Buffer
+
+		printer := NewRESPPrinter(&buf)
+		Expect(printer.StreamData(&rdb.StreamData{
+			DataKey: rdb.DataKey{Key: "a"},
+			Groups:  []rdb.StreamGroup{{Name: "g"}},
+		})).To(Succeed())
+		Expect(printer.StreamData(&rdb.StreamData{
+			DataKey: rdb.DataKey{Key: "b"},
+		})).To(Succeed())
+		Expect(readCommands(&buf)).To(Equal([][]string{
+			{"XGROUP", "CREATE", "a", "g", "0-0", "MKSTREAM"},
+			{"XGROUP", "CREATE", "b", "rdb", "0", "MKSTREAM"},
+			{"XGROUP", "DESTROY", "b", "rdb"},
+		}))
+	})
+
+	It("should return error for module values", func() {
+		var buf bytes.Buffer
+
+		printer := NewRESPPrinter(&buf)
+		file, err := os.Open("../../fixtures/bloom_filter.rdb")
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+		Expect(printParserData(file, printer)).To(MatchError(ContainSubstring(`*rdb.BloomFilter of key "newFilter"`)))
+	})
+
+	It("should split big collections into chunks", func() {
+		var buf bytes.Buffer
+
+		printer := NewRESPPrinter(&buf)
+		key := rdb.DataKey{Key: "set"}
+		Expect(printer.SetHead(&rdb.SetHead{DataKey: key, Length: 1000})).To(Succeed())
+
+		for i := 0; i < 1000; i++ {
+			Expect(printer.SetEntry(&rdb.SetEntry{DataKey: key, Value: strconv.Itoa(i)})).To(Succeed())
+		}
+
+		Expect(printer.SetData(&rdb.SetData{DataKey: key})).To(Succeed())
+
+		commands := readCommands(&buf)
+		Expect(commands).To(HaveLen(2))
+		Expect(commands[0]).To(HaveLen(2 + respChunkSize))
+		Expect(commands[0][:3]).To(Equal([]string{"SADD", "set", "0"}))
+		Expect(commands[1]).To(HaveLen(2 + 1000 - respChunkSize))
+		Expect(commands[1][:3]).To(Equal([]string{"SADD", "set", strconv.Itoa(respChunkSize)}))
+	})
+})
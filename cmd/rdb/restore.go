@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tommy351/rdb-go"
+)
+
+// printRestoreCommands prints a RESTORE command in RESP for each key, and a
+// SELECT command when the database is changed. Expiries are absolute, so they
+// are not extended by the time spent on the migration.
+func printRestoreCommands(reader io.Reader, w io.Writer) error {
+	parser := rdb.NewParser(reader)
+	parser.RawValues = true
+	db := 0
+
+	for {
+		data, err := parser.Next()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("parser error: %w", err)
+		}
+
+		raw, ok := data.(*rdb.RawData)
+		if !ok {
+			continue
+		}
+
+		if raw.Database != db {
+			if err := writeCommand(w, "SELECT", strconv.Itoa(raw.Database)); err != nil {
+				return err
+			}
+
+			db = raw.Database
+		}
+
+		if err := writeCommand(w, restoreArgs(raw, parser.Version())...); err != nil {
+			return err
+		}
+	}
+}
+
+func restoreArgs(raw *rdb.RawData, version int) []string {
+	ttl := "0"
+
+	if raw.Expiry != nil {
+		ttl = formatMilliseconds(*raw.Expiry)
+	}
+
+	args := []string{
+		"RESTORE", raw.Key, ttl,
+		string(rdb.DumpPayload(raw.Value, version)), "REPLACE", "ABSTTL",
+	}
+
+	if raw.Idle != nil {
+		args = append(args, "IDLETIME", strconv.FormatInt(int64(*raw.Idle/time.Second), 10))
+	}
+
+	if raw.Freq != nil {
+		args = append(args, "FREQ", strconv.FormatUint(uint64(*raw.Freq), 10))
+	}
+
+	return args
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tommy351/rdb-go"
+)
+
+// readCommands reads commands written by writeCommand.
+func readCommands(r io.Reader) [][]string {
+	var commands [][]string
+
+	reader := bufio.NewReader(r)
+
+	readLine := func() string {
+		line, err := reader.ReadString('\n')
+		Expect(err).NotTo(HaveOccurred())
+
+		return strings.TrimSuffix(line, "\r\n")
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return commands
+		}
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(line).To(HavePrefix("*"))
+
+		length, err := strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		command := make([]string, length)
+
+		for i := range command {
+			size, err := strconv.Atoi(strings.TrimPrefix(readLine(), "$"))
+			Expect(err).NotTo(HaveOccurred())
+
+			buf := make([]byte, size+2)
+			_, err = io.ReadFull(reader, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf[size:])).To(Equal("\r\n"))
+
+			command[i] = string(buf[:size])
+		}
+
+		commands = append(commands, command)
+	}
+}
+
+var _ = Describe("printRestoreCommands", func() {
+	print := func(name string) [][]string {
+		var buf bytes.Buffer
+
+		file, err := os.Open("../../fixtures/" + name + ".rdb")
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+		Expect(printRestoreCommands(file, &buf)).To(Succeed())
+
+		return readCommands(&buf)
+	}
+
+	It("should print RESTORE commands with absolute TTL", func() {
+		commands := print("keys_with_expiry")
+		Expect(commands).To(HaveLen(1))
+
+		command := commands[0]
+		Expect(command[:3]).To(Equal([]string{"RESTORE", "expires_ms_precision", "1671963072573"}))
+		Expect(command[4:]).To(Equal([]string{"REPLACE", "ABSTTL"}))
+
+		value, err := rdb.ParseDumpPayload([]byte(command[3]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(&rdb.StringData{Value: "2022-12-25 10:11:12.573 UTC"}))
+	})
+
+	It("should print SELECT when the database is changed", func() {
+		commands := print("multiple_databases")
+		Expect(commands).To(HaveLen(3))
+		Expect(commands[0][:3]).To(Equal([]string{"RESTORE", "key_in_zeroth_database", "0"}))
+		Expect(commands[1]).To(Equal([]string{"SELECT", "2"}))
+		Expect(commands[2][:3]).To(Equal([]string{"RESTORE", "key_in_second_database", "0"}))
+	})
+
+	It("should print IDLETIME and FREQ when they are saved", func() {
+		idle := print("redis_62_with_lru_idle")
+		Expect(idle).To(HaveLen(3))
+		Expect(idle[0][1]).To(Equal("cold"))
+		Expect(idle[0][4:]).To(Equal([]string{"REPLACE", "ABSTTL", "IDLETIME", "3600"}))
+		Expect(idle[2][1]).To(Equal("plain"))
+		Expect(idle[2][4:]).To(Equal([]string{"REPLACE", "ABSTTL"}))
+
+		freq := print("redis_62_with_lfu_freq")
+		Expect(freq).To(HaveLen(3))
+		Expect(freq[0][1]).To(Equal("hot"))
+		Expect(freq[0][4:]).To(Equal([]string{"REPLACE", "ABSTTL", "FREQ", "5"}))
+	})
+})
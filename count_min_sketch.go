@@ -0,0 +1,78 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	redisBloomCountMinSketchModule          = "CMSk-TYPE"
+	redisBloomCountMinSketchEncodingVersion = 0
+)
+
+// CountMinSketch represents a count-min sketch implemented by RedisBloom.
+// Counters contains Depth rows of Width counters.
+type CountMinSketch struct {
+	DataKey
+	Width    uint64
+	Depth    uint64
+	Count    uint64
+	Counters []uint32
+}
+
+// Query returns the estimated count of the item, in the same way as
+// CMS.QUERY.
+func (c *CountMinSketch) Query(item []byte) uint32 {
+	if c.Width == 0 || c.Depth == 0 || uint64(len(c.Counters)) < c.Width*c.Depth {
+		return 0
+	}
+
+	result := uint32(math.MaxUint32)
+
+	for i := uint64(0); i < c.Depth; i++ {
+		hash := murmurHash2(item, uint32(i))
+		value := c.Counters[uint64(hash)%c.Width+i*c.Width]
+
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
+
+type countMinSketchDecoder struct{}
+
+// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/src/rm_cms.c
+func (countMinSketchDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion > redisBloomCountMinSketchEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisBloomCountMinSketchModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	var err error
+
+	cms := &CountMinSketch{DataKey: key}
+
+	for _, field := range []*uint64{&cms.Width, &cms.Depth, &cms.Count} {
+		if *field, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	cms.Counters = make([]uint32, len(data)/4)
+
+	for i := range cms.Counters {
+		cms.Counters[i] = binary.LittleEndian.Uint32([]byte(data[i*4 : i*4+4]))
+	}
+
+	return cms, nil
+}
@@ -0,0 +1,111 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// dumpFooterLength is the length of the RDB version and the CRC64 checksum at
+// the end of a DUMP payload.
+const dumpFooterLength = 10
+
+// RawData contains the serialized value of a key, which is the data type
+// followed by the encoded value exactly as saved in the RDB file. It is
+// returned instead of decoded data when Parser.RawValues is true.
+type RawData struct {
+	DataKey
+	Value []byte
+}
+
+// DumpPayload returns the payload of the serialized value as returned by the
+// DUMP command, which can be restored with the RESTORE command by Redis whose
+// RDB version is not older than the given version.
+func DumpPayload(value []byte, version int) []byte {
+	payload := make([]byte, 0, len(value)+dumpFooterLength)
+	payload = append(payload, value...)
+	payload = appendUint16(payload, uint16(version))
+
+	return appendUint64(payload, updateCRC64(0, payload))
+}
+
+// ParseDumpPayload parses a value serialized by the DUMP command, which is
+// followed by a 2-byte RDB version and a CRC64 checksum. It returns the value
+// as one of the following types, whose key is empty:
+//
+//	*StringData
+//	*ListData
+//	*SetData
+//	*SortedSetData
+//	*HashData
+//	*StreamData
+//	*BloomFilter, *CuckooFilter, *TopK, *TDigest, *CountMinSketch
+//	*JSONData
+//	*TimeSeriesData
+//	*ModuleData
+func ParseDumpPayload(payload []byte) (interface{}, error) {
+	if len(payload) < dumpFooterLength+1 {
+		return nil, fmt.Errorf("%w: payload is too short", ErrInvalidDumpPayload)
+	}
+
+	body := payload[:len(payload)-dumpFooterLength]
+	footer := payload[len(payload)-dumpFooterLength:]
+	version := int(binary.LittleEndian.Uint16(footer))
+
+	if version < minVersion || version > maxVersion {
+		return nil, UnsupportedVersionError{Version: version}
+	}
+
+	expected := binary.LittleEndian.Uint64(footer[2:])
+	actual := updateCRC64(0, payload[:len(payload)-8])
+
+	if expected != actual {
+		return nil, ChecksumMismatchError{Expected: expected, Actual: actual}
+	}
+
+	dataType := body[0]
+
+	if minVersion, ok := dataTypeMinVersions[dataType]; ok && version < minVersion {
+		return nil, UnsupportedDataTypeError{DataType: dataType, Version: version}
+	}
+
+	p := NewParser(bytes.NewReader(body[1:]))
+	p.initialized = true
+	p.version = version
+	p.db = 0
+	p.dataType = &dataType
+
+	var (
+		result interface{}
+		chunks []TimeSeriesChunk
+	)
+
+	for p.dataType != nil {
+		data, err := p.readData()
+		if errors.Is(err, errContinueLoop) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Chunks of time series are returned one at a time by the parser
+		if entry, ok := data.(*TimeSeriesEntry); ok {
+			chunks = append(chunks, entry.TimeSeriesChunk)
+		}
+
+		result = data
+	}
+
+	if ts, ok := result.(*TimeSeriesData); ok {
+		ts.Chunks = chunks
+	}
+
+	if remaining := int64(len(body)-1) - p.source.Position(); remaining != 0 {
+		return nil, fmt.Errorf("%w: %d bytes left after the value", ErrInvalidDumpPayload, remaining)
+	}
+
+	return result, nil
+}
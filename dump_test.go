@@ -0,0 +1,180 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseDumpPayload", func() {
+	It("should parse a payload returned by DUMP", func() {
+		// DUMP of the integer 10 in Redis 5
+		data, err := ParseDumpPayload([]byte("\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(&StringData{Value: "10"}))
+	})
+
+	It("should return the aggregated value of collections", func() {
+		encoder := &valueEncoder{version: 10}
+		dataType, value, err := encoder.encode(&ListData{Value: []string{"a", "1", "b"}}, EncodingListPack)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := ParseDumpPayload(DumpPayload(append([]byte{dataType}, value...), 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(&ListData{Value: []string{"a", "1", "b"}}))
+	})
+
+	It("should return ModuleData when the module has no decoder", func() {
+		module := &ModuleData{
+			ModuleName:      "test-type",
+			EncodingVersion: 1,
+			Values: []ModuleValue{
+				{Type: ModuleValueUInt, Value: uint64(5)},
+				{Type: ModuleValueString, Value: "foo"},
+			},
+		}
+
+		dataType, value, err := (&valueEncoder{version: 9}).encode(module, EncodingPlain)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := ParseDumpPayload(DumpPayload(append([]byte{dataType}, value...), 9))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(module))
+	})
+
+	It("should return ChecksumMismatchError when checksum is invalid", func() {
+		payload := DumpPayload([]byte{typeString, 3, 'f', 'o', 'o'}, 9)
+		binary.LittleEndian.PutUint64(payload[len(payload)-8:], 1)
+
+		_, err := ParseDumpPayload(payload)
+		Expect(err).To(BeAssignableToTypeOf(ChecksumMismatchError{}))
+	})
+
+	It("should return UnsupportedVersionError when version is too new", func() {
+		_, err := ParseDumpPayload(DumpPayload([]byte{typeString, 0}, maxVersion+1))
+		Expect(err).To(Equal(UnsupportedVersionError{Version: maxVersion + 1}))
+	})
+
+	It("should return UnsupportedDataTypeError when the data type is too new for the version", func() {
+		_, err := ParseDumpPayload(DumpPayload([]byte{typeSetListPack, 0}, 10))
+		Expect(err).To(Equal(UnsupportedDataTypeError{DataType: typeSetListPack, Version: 10}))
+	})
+
+	It("should return ErrInvalidDumpPayload when payload is too short", func() {
+		_, err := ParseDumpPayload([]byte{9, 0})
+		Expect(errors.Is(err, ErrInvalidDumpPayload)).To(BeTrue())
+	})
+
+	It("should return ErrInvalidDumpPayload when bytes are left after the value", func() {
+		_, err := ParseDumpPayload(DumpPayload([]byte{typeString, 0, 0}, 9))
+		Expect(errors.Is(err, ErrInvalidDumpPayload)).To(BeTrue())
+	})
+})
+
+var _ = Describe("RawValues", func() {
+	isValue := func(data interface{}) bool {
+		switch data.(type) {
+		case *ListHead, *ListEntry, *SetHead, *SetEntry, *SortedSetHead, *SortedSetEntry,
+			*HashHead, *HashEntry, *StreamHead, *StreamEntry:
+			return false
+		}
+
+		return reflect.ValueOf(data).Elem().FieldByName("DataKey").IsValid()
+	}
+
+	readAll := func(name string, raw bool) ([]interface{}, int) {
+		file, err := os.Open(fmt.Sprintf("fixtures/%s.rdb", name))
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		var result []interface{}
+
+		parser := NewParser(file)
+		parser.RawValues = raw
+
+		for {
+			data, err := parser.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+
+			if isValue(data) {
+				result = append(result, data)
+			}
+		}
+
+		return result, parser.Version()
+	}
+
+	for _, name := range []string{
+		"keys_with_expiry",
+		"multiple_databases",
+		"ziplist_that_compresses_easily",
+		"intset_64",
+		"regular_sorted_set",
+		"zipmap_that_doesnt_compress",
+		"quicklist",
+		"redis_50_with_streams",
+		"redis_72_with_listpacks",
+		"redis_74_with_hash_field_expiry",
+		"bloom_filter",
+		"redis_json",
+		"redis_40_with_module",
+	} {
+		name := name
+
+		It(fmt.Sprintf("should return values of %s which can be parsed as DUMP payloads", name), func() {
+			expected, _ := readAll(name, false)
+			actual, version := readAll(name, true)
+			Expect(actual).To(HaveLen(len(expected)))
+
+			for i, data := range actual {
+				raw, ok := data.(*RawData)
+				Expect(ok).To(BeTrue())
+
+				value, err := ParseDumpPayload(DumpPayload(raw.Value, version))
+				Expect(err).NotTo(HaveOccurred())
+
+				reflect.ValueOf(value).Elem().FieldByName("DataKey").Set(reflect.ValueOf(raw.DataKey))
+				Expect(value).To(Equal(expected[i]))
+			}
+		})
+	}
+
+	It("should return the data type and the value as saved in the file", func() {
+		parser := NewParser(bytes.NewReader([]byte("REDIS0009\xfe\x00\x00\x03foo\x03bar\xff")))
+		parser.RawValues = true
+		parser.SkipChecksum = true
+
+		_, err := parser.Next()
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := parser.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(&RawData{
+			DataKey: DataKey{Key: "foo"},
+			Value:   []byte("\x00\x03bar"),
+		}))
+	})
+
+	It("should return UnsupportedDataTypeError for unknown data types", func() {
+		parser := NewParser(bytes.NewReader([]byte("REDIS0009\xfe\x00\x1a\x03foo\x03bar\x00\x03baz\x03qux\xff")))
+		parser.RawValues = true
+		parser.SkipChecksum = true
+
+		_, err := parser.Next()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = parser.Next()
+		Expect(errors.Is(err, UnsupportedDataTypeError{DataType: 26})).To(BeTrue())
+	})
+})
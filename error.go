@@ -9,6 +9,14 @@ import (
 // the magic string "REDIS".
 var ErrInvalidMagicString = errors.New("invalid magic string")
 
+// ErrUntypedModuleValue is returned by ModuleReader.ReadValue when module
+// values are saved without opcodes.
+var ErrUntypedModuleValue = errors.New("untyped module value")
+
+// ErrInvalidDumpPayload is returned by ParseDumpPayload when a payload is not
+// a value serialized by the DUMP command.
+var ErrInvalidDumpPayload = errors.New("invalid dump payload")
+
 type UnsupportedVersionError struct {
 	Version int
 }
@@ -17,6 +25,15 @@ func (u UnsupportedVersionError) Error() string {
 	return fmt.Sprintf("unsupported version %d", u.Version)
 }
 
+type UnsupportedOpCodeError struct {
+	OpCode  byte
+	Version int
+}
+
+func (u UnsupportedOpCodeError) Error() string {
+	return fmt.Sprintf("opcode %d is not supported in RDB version %d", u.OpCode, u.Version)
+}
+
 type IntSetEncodingError struct {
 	Encoding uint32
 }
@@ -42,10 +59,21 @@ func (s StringEncodingError) Error() string {
 }
 
 type UnsupportedDataTypeError struct {
-	DataType byte
+	DataType        byte
+	ModuleName      string
+	EncodingVersion int
+	Version         int
 }
 
 func (u UnsupportedDataTypeError) Error() string {
+	if u.ModuleName != "" {
+		return fmt.Sprintf("unsupported module %s encver %d", u.ModuleName, u.EncodingVersion)
+	}
+
+	if u.Version != 0 {
+		return fmt.Sprintf("data type %d is not supported in RDB version %d", u.DataType, u.Version)
+	}
+
 	return fmt.Sprintf("unsupported data type %d", u.DataType)
 }
 
@@ -90,3 +118,134 @@ type ModuleOpcodeError struct {
 func (r ModuleOpcodeError) Error() string {
 	return fmt.Sprintf("illegal rdbModuleOpcode %d, expect:%d", r.Actual, r.Expected)
 }
+
+type ListPackHeaderError struct {
+	Header byte
+}
+
+func (l ListPackHeaderError) Error() string {
+	return fmt.Sprintf("invalid listpack entry header %d", l.Header)
+}
+
+type ListPackEndError struct {
+	Value byte
+}
+
+func (l ListPackEndError) Error() string {
+	return fmt.Sprintf("invalid listpack end %d", l.Value)
+}
+
+type StreamIDLengthError struct {
+	Length int
+}
+
+func (s StreamIDLengthError) Error() string {
+	return fmt.Sprintf("invalid stream ID length %d", s.Length)
+}
+
+type StreamPendingEntryNotFoundError struct {
+	Consumer string
+	ID       StreamID
+}
+
+func (s StreamPendingEntryNotFoundError) Error() string {
+	return fmt.Sprintf("pending entry %s of stream consumer %q is not found in the group", s.ID, s.Consumer)
+}
+
+type ListPackLengthError struct {
+	Length      int
+	ValueLength int
+}
+
+func (l ListPackLengthError) Error() string {
+	return fmt.Sprintf("invalid listpack length %d, expected to be divisible by %d", l.Length, l.ValueLength)
+}
+
+type QuickListContainerError struct {
+	Container int
+}
+
+func (q QuickListContainerError) Error() string {
+	return fmt.Sprintf("invalid quicklist node container %d", q.Container)
+}
+
+type ChecksumMismatchError struct {
+	Expected uint64
+	Actual   uint64
+}
+
+func (c ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch, expected %016x, actual %016x", c.Expected, c.Actual)
+}
+
+type InvalidModuleNameError struct {
+	Name string
+}
+
+func (i InvalidModuleNameError) Error() string {
+	return fmt.Sprintf("invalid module name %q", i.Name)
+}
+
+type InvalidModuleEncodingVersionError struct {
+	EncodingVersion int
+}
+
+func (i InvalidModuleEncodingVersionError) Error() string {
+	return fmt.Sprintf("invalid module encoding version %d", i.EncodingVersion)
+}
+
+type JSONNodeTypeError struct {
+	Type int64
+}
+
+func (j JSONNodeTypeError) Error() string {
+	return fmt.Sprintf("invalid json node type %d", j.Type)
+}
+
+type TimeSeriesAggregationError struct {
+	Aggregation uint64
+}
+
+func (t TimeSeriesAggregationError) Error() string {
+	return fmt.Sprintf("unsupported time series aggregation %d", t.Aggregation)
+}
+
+type UnsupportedLegacyModuleError struct {
+	ModuleName      string
+	EncodingVersion int
+	Offset          int64
+}
+
+func (u UnsupportedLegacyModuleError) Error() string {
+	return fmt.Sprintf("unsupported legacy module %s encver %d at offset %d", u.ModuleName, u.EncodingVersion, u.Offset)
+}
+
+type FunctionHeaderError struct {
+	Header string
+}
+
+func (f FunctionHeaderError) Error() string {
+	return fmt.Sprintf("invalid function library header %q", f.Header)
+}
+
+type UnsupportedEncodingError struct {
+	DataType string
+	Encoding Encoding
+	Version  int
+}
+
+func (u UnsupportedEncodingError) Error() string {
+	if u.Version > 0 {
+		return fmt.Sprintf("%s can not be written as %s in RDB version %d", u.DataType, u.Encoding, u.Version)
+	}
+
+	return fmt.Sprintf("%s can not be written as %s", u.DataType, u.Encoding)
+}
+
+type IntSetValueError struct {
+	Value string
+}
+
+func (i IntSetValueError) Error() string {
+	return fmt.Sprintf("value %q can not be saved in an intset", i.Value)
+}
@@ -0,0 +1,92 @@
+package rdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	functionShebang    = "#!"
+	functionNamePrefix = "name="
+)
+
+// FunctionLibrary contains a library loaded by FUNCTION LOAD in Redis 7.
+type FunctionLibrary struct {
+	Engine string
+	Name   string
+	Code   string
+}
+
+// readFunction2 reads a library saved with RDB_OPCODE_FUNCTION2. Only the code
+// is saved, and the engine and the name are parsed from the shebang line, e.g.
+// "#!lua name=mylib".
+//
+// https://github.com/redis/redis/blob/7.0.0/src/functions.c
+func readFunction2(r byteReader) (*FunctionLibrary, error) {
+	code, err := readString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function code: %w", err)
+	}
+
+	header := code
+
+	if i := strings.IndexByte(header, '\n'); i >= 0 {
+		header = header[:i]
+	}
+
+	if !strings.HasPrefix(header, functionShebang) {
+		return nil, FunctionHeaderError{Header: header}
+	}
+
+	parts := strings.Fields(header[len(functionShebang):])
+	if len(parts) == 0 {
+		return nil, FunctionHeaderError{Header: header}
+	}
+
+	lib := &FunctionLibrary{Engine: parts[0], Code: code}
+
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, functionNamePrefix) {
+			lib.Name = part[len(functionNamePrefix):]
+		}
+	}
+
+	if lib.Name == "" {
+		return nil, FunctionHeaderError{Header: header}
+	}
+
+	return lib, nil
+}
+
+// readFunctionPreGA reads a function saved with RDB_OPCODE_FUNCTION_PRE_GA by
+// release candidates of Redis 7, which are saved with the name, the engine,
+// the optional description and the code. The description is discarded.
+func readFunctionPreGA(r byteReader) (*FunctionLibrary, error) {
+	name, err := readString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function name: %w", err)
+	}
+
+	engine, err := readString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function engine: %w", err)
+	}
+
+	hasDescription, err := readLength(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function description flag: %w", err)
+	}
+
+	if hasDescription > 0 {
+		if err := skipString(r); err != nil {
+			return nil, fmt.Errorf("failed to read function description: %w", err)
+		}
+	}
+
+	code, err := readString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function code: %w", err)
+	}
+
+	return &FunctionLibrary{Engine: engine, Name: name, Code: code}, nil
+}
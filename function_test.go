@@ -0,0 +1,38 @@
+package rdb
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FunctionLibrary", func() {
+	encodeString := func(s string) []byte {
+		// Strings shorter than 64 bytes are saved with a 6-bit length
+		return append([]byte{byte(len(s))}, s...)
+	}
+
+	DescribeTable("readFunction2", func(code string, expected *FunctionLibrary) {
+		Expect(readFunction2(newSliceReader(encodeString(code)))).To(Equal(expected))
+	},
+		Entry("without body", "#!lua name=mylib", &FunctionLibrary{
+			Engine: "lua",
+			Name:   "mylib",
+			Code:   "#!lua name=mylib",
+		}),
+		Entry("with extra spaces", "#!lua  name=mylib \nreturn", &FunctionLibrary{
+			Engine: "lua",
+			Name:   "mylib",
+			Code:   "#!lua  name=mylib \nreturn",
+		}),
+	)
+
+	DescribeTable("readFunction2 errors", func(code string, expected error) {
+		_, err := readFunction2(newSliceReader(encodeString(code)))
+		Expect(err).To(Equal(expected))
+	},
+		Entry("without shebang", "lua name=mylib\nreturn", FunctionHeaderError{Header: "lua name=mylib"}),
+		Entry("without engine", "#!\nreturn", FunctionHeaderError{Header: "#!"}),
+		Entry("without name", "#!lua\nreturn", FunctionHeaderError{Header: "#!lua"}),
+	)
+})
@@ -2,21 +2,41 @@ package rdb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/tommy351/rdb-go/internal/convert"
 )
 
-// HashValue contains a key-value pair of a hash entry.
+const (
+	// hashNoTTL is used to indicate no TTL is set for a hash field.
+	hashNoTTL = 0
+)
+
+// HashValue contains a key-value pair of a hash entry. Expiry is only available
+// when the field has a TTL, which is supported since Redis 7.4, or an expiry set
+// by EXPIREMEMBER in KeyDB.
 type HashValue struct {
-	Index string
-	Value string
+	Index  string
+	Value  string
+	Expiry *time.Time
+}
+
+// Expired returns true if the field is expired.
+func (h HashValue) Expired() bool {
+	if h.Expiry == nil {
+		return false
+	}
+
+	return time.Now().After(*h.Expiry)
 }
 
 // HashHead contains the key and the length of a hash. It is returned when a hash
-// is read first time.
+// is read first time. MinExpiry is the minimum expiry of all fields in the hash,
+// it is only available when it is stored in the RDB file.
 type HashHead struct {
 	DataKey
-	Length int
+	Length    int
+	MinExpiry *time.Time
 }
 
 // HashEntry is returned when a new hash entry is read.
@@ -26,10 +46,14 @@ type HashEntry struct {
 	Length int
 }
 
-// HashData is returned when all entries in a hash are all read.
+// HashData is returned when all entries in a hash are all read. FieldExpiry
+// contains the expiry of fields which have a TTL, and MinExpiry is the minimum of
+// them. Both are nil when no fields have a TTL.
 type HashData struct {
 	DataKey
-	Value map[string]string
+	Value       map[string]string
+	FieldExpiry map[string]time.Time
+	MinExpiry   *time.Time
 }
 
 type hashValueReader struct{}
@@ -51,12 +75,15 @@ func (hashValueReader) ReadValue(r byteReader) (interface{}, error) {
 	}, nil
 }
 
-type hashMapper struct{}
+type hashMapper struct {
+	MinExpiry *time.Time
+}
 
-func (hashMapper) MapHead(head *collectionHead) (interface{}, error) {
+func (h hashMapper) MapHead(head *collectionHead) (interface{}, error) {
 	return &HashHead{
-		DataKey: head.DataKey,
-		Length:  head.Length,
+		DataKey:   head.DataKey,
+		Length:    head.Length,
+		MinExpiry: h.MinExpiry,
 	}, nil
 }
 
@@ -77,6 +104,20 @@ func (hashMapper) MapSlice(slice *collectionSlice) (interface{}, error) {
 	for _, v := range slice.Value {
 		v := v.(HashValue)
 		data.Value[v.Index] = v.Value
+
+		if v.Expiry == nil {
+			continue
+		}
+
+		if data.FieldExpiry == nil {
+			data.FieldExpiry = map[string]time.Time{}
+		}
+
+		data.FieldExpiry[v.Index] = *v.Expiry
+
+		if data.MinExpiry == nil || v.Expiry.Before(*data.MinExpiry) {
+			data.MinExpiry = v.Expiry
+		}
 	}
 
 	return data, nil
@@ -110,3 +151,76 @@ func (hashZipListValueReader) ReadValue(r byteReader) (interface{}, error) {
 		Value: valueString,
 	}, nil
 }
+
+type hashListPackValueReader struct{}
+
+func (hashListPackValueReader) ReadValue(r byteReader) (interface{}, error) {
+	key, err := readListPackString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash key from listpack: %w", err)
+	}
+
+	value, err := readListPackString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash value from listpack: %w", err)
+	}
+
+	return HashValue{
+		Index: key,
+		Value: value,
+	}, nil
+}
+
+// hashMetadataValueReader reads hash fields with TTL. The TTL is relative to
+// MinExpiry when MinExpiry is not nil, otherwise it is an absolute time.
+type hashMetadataValueReader struct {
+	MinExpiry *time.Time
+}
+
+func (h hashMetadataValueReader) ReadValue(r byteReader) (interface{}, error) {
+	ttl, err := readLength(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash field ttl: %w", err)
+	}
+
+	value, err := hashValueReader{}.ReadValue(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hashValue := value.(HashValue)
+
+	if ttl != hashNoTTL {
+		expiry := int64(ttl)
+
+		if h.MinExpiry != nil {
+			expiry += h.MinExpiry.UnixNano()/int64(time.Millisecond) - 1
+		}
+
+		hashValue.Expiry = millisecondsToTime(expiry)
+	}
+
+	return hashValue, nil
+}
+
+type hashListPackExValueReader struct{}
+
+func (hashListPackExValueReader) ReadValue(r byteReader) (interface{}, error) {
+	value, err := hashListPackValueReader{}.ReadValue(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := readListPackInteger(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash field ttl from listpack: %w", err)
+	}
+
+	hashValue := value.(HashValue)
+
+	if ttl != hashNoTTL {
+		hashValue.Expiry = millisecondsToTime(ttl)
+	}
+
+	return hashValue, nil
+}
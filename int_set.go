@@ -3,6 +3,7 @@ package rdb
 import (
 	"fmt"
 	"io"
+	"math"
 )
 
 type intSetIterator struct {
@@ -100,3 +101,33 @@ func (i *intSetIterator) readValue() (interface{}, error) {
 
 	return nil, IntSetEncodingError{Encoding: i.encoding}
 }
+
+// encodeIntSet returns an intset containing values, which must be sorted.
+func encodeIntSet(values []int64) []byte {
+	var encoding uint32 = 2
+
+	for _, v := range values {
+		switch {
+		case v < math.MinInt32 || v > math.MaxInt32:
+			encoding = 8
+		case (v < math.MinInt16 || v > math.MaxInt16) && encoding < 4:
+			encoding = 4
+		}
+	}
+
+	buf := appendUint32(nil, encoding)
+	buf = appendUint32(buf, uint32(len(values)))
+
+	for _, v := range values {
+		switch encoding {
+		case 2:
+			buf = appendUint16(buf, uint16(v))
+		case 4:
+			buf = appendUint32(buf, uint32(v))
+		default:
+			buf = appendUint64(buf, uint64(v))
+		}
+	}
+
+	return buf
+}
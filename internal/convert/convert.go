@@ -34,6 +34,22 @@ func Float64(value interface{}) (float64, error) {
 	return 0, Error{Value: value, Type: "float64"}
 }
 
+func Int64(value interface{}) (int64, error) {
+	v := reflect.ValueOf(value)
+
+	// nolint: exhaustive
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.String:
+		return strconv.ParseInt(v.String(), 10, 64)
+	}
+
+	return 0, Error{Value: value, Type: "int64"}
+}
+
 func String(value interface{}) (string, error) {
 	v := reflect.ValueOf(value)
 
@@ -0,0 +1,259 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	redisJSONModule = "ReJSON-RL"
+
+	// Documents are saved as a tree of nodes by RedisJSON 1.x.
+	redisJSONLegacyEncodingVersion = 0
+	// Documents are saved as serialized strings, followed by the index info of
+	// RediSearch in version 2.
+	redisJSONIndexEncodingVersion = 2
+	redisJSONEncodingVersion      = 3
+
+	// Node types of RedisJSON 1.x.
+	redisJSONNodeNull    = 0x1
+	redisJSONNodeString  = 0x2
+	redisJSONNodeNumber  = 0x4
+	redisJSONNodeInteger = 0x8
+	redisJSONNodeBoolean = 0x10
+	redisJSONNodeDict    = 0x20
+	redisJSONNodeArray   = 0x40
+	redisJSONNodeKeyVal  = 0x80
+)
+
+// JSONData contains the document saved by RedisJSON. Value is one of the
+// following types:
+//
+//	nil
+//	bool
+//	int64
+//	uint64, for integers larger than math.MaxInt64
+//	float64
+//	json.Number, for integers which don't fit in 64 bits
+//	string
+//	[]interface{}
+//	map[string]interface{}
+type JSONData struct {
+	DataKey
+	Value interface{}
+}
+
+type jsonDecoder struct{}
+
+// https://github.com/RedisJSON/RedisJSON/blob/master/redis_json/src/redisjson.rs
+func (jsonDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch encodingVersion {
+	case redisJSONLegacyEncodingVersion:
+		value, err = readLegacyJSONNode(r)
+
+	case redisJSONIndexEncodingVersion, redisJSONEncodingVersion:
+		value, err = readJSONString(r, encodingVersion)
+
+	default:
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisJSONModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONData{DataKey: key, Value: value}, nil
+}
+
+func readJSONString(r *ModuleReader, encodingVersion int) (interface{}, error) {
+	data, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	if encodingVersion == redisJSONIndexEncodingVersion {
+		hasIndex, err := r.ReadUnsigned()
+		if err != nil {
+			return nil, err
+		}
+
+		// index name and path
+		if hasIndex > 0 {
+			for i := 0; i < 2; i++ {
+				if _, err := r.ReadString(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(data))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	return normalizeJSONNumbers(value), nil
+}
+
+// normalizeJSONNumbers converts json.Number into int64, uint64 or float64.
+// Integers which don't fit in 64 bits are kept as json.Number because they
+// would lose precision as float64.
+func normalizeJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u
+		}
+
+		if isJSONInteger(v.String()) {
+			return v
+		}
+
+		f, _ := v.Float64()
+
+		return f
+
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalizeJSONNumbers(elem)
+		}
+
+	case map[string]interface{}:
+		for k, elem := range v {
+			v[k] = normalizeJSONNumbers(elem)
+		}
+	}
+
+	return value
+}
+
+func isJSONInteger(s string) bool {
+	return !strings.ContainsAny(s, ".eE")
+}
+
+// nolint: gocyclo
+func readLegacyJSONNode(r *ModuleReader) (interface{}, error) {
+	nodeType, err := readLegacyJSONInt64(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read json node type: %w", err)
+	}
+
+	switch nodeType {
+	case redisJSONNodeNull:
+		return nil, nil
+
+	case redisJSONNodeString:
+		return r.ReadString()
+
+	case redisJSONNodeNumber:
+		return readLegacyJSONFloat64(r)
+
+	case redisJSONNodeInteger:
+		return readLegacyJSONInt64(r)
+
+	case redisJSONNodeBoolean:
+		value, err := r.ReadString()
+		if err != nil {
+			return nil, err
+		}
+
+		return strconv.ParseBool(value)
+
+	case redisJSONNodeDict:
+		length, err := readLegacyJSONInt64(r)
+		if err != nil {
+			return nil, err
+		}
+
+		dict := make(map[string]interface{}, length)
+
+		for i := int64(0); i < length; i++ {
+			if err := readLegacyJSONKeyVal(r, dict); err != nil {
+				return nil, err
+			}
+		}
+
+		return dict, nil
+
+	case redisJSONNodeArray:
+		length, err := readLegacyJSONInt64(r)
+		if err != nil {
+			return nil, err
+		}
+
+		array := make([]interface{}, length)
+
+		for i := range array {
+			if array[i], err = readLegacyJSONNode(r); err != nil {
+				return nil, err
+			}
+		}
+
+		return array, nil
+	}
+
+	return nil, JSONNodeTypeError{Type: nodeType}
+}
+
+func readLegacyJSONKeyVal(r *ModuleReader, dict map[string]interface{}) error {
+	nodeType, err := readLegacyJSONInt64(r)
+	if err != nil {
+		return fmt.Errorf("failed to read json node type: %w", err)
+	}
+
+	if nodeType != redisJSONNodeKeyVal {
+		return JSONNodeTypeError{Type: nodeType}
+	}
+
+	key, err := r.ReadString()
+	if err != nil {
+		return err
+	}
+
+	if dict[key], err = readLegacyJSONNode(r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readLegacyJSONInt64 reads an integer of a node. Values saved as
+// RDB_TYPE_MODULE have no opcodes, so the type saved by RedisJSON 1.x is
+// assumed.
+func readLegacyJSONInt64(r *ModuleReader) (int64, error) {
+	if r.Legacy() {
+		return r.ReadSigned()
+	}
+
+	return readModuleInt64(r)
+}
+
+// readLegacyJSONFloat64 reads a number of a node in the same way as
+// readLegacyJSONInt64.
+func readLegacyJSONFloat64(r *ModuleReader) (float64, error) {
+	if r.Legacy() {
+		return r.ReadDouble()
+	}
+
+	return readModuleFloat64(r)
+}
@@ -0,0 +1,176 @@
+package rdb
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// KeyDB saves expiries of members as pairs of aux fields after the value.
+	keyDBSubExpireKey  = "keydb-subexpire-key"
+	keyDBSubExpireWhen = "keydb-subexpire-when"
+)
+
+func isKeyDBMemberExpiryAux(key string) bool {
+	return key == keyDBSubExpireKey || key == keyDBSubExpireWhen
+}
+
+// readKeyDBMemberExpiry buffers events of a set, a sorted set or a hash until
+// all entries are read, then reads the expiries of members saved after the
+// value and attaches them to the buffered events.
+//
+// https://github.com/Snapchat/KeyDB/blob/v6.3.4/src/rdb.cpp
+func (p *Parser) readKeyDBMemberExpiry(data interface{}) (interface{}, error) {
+	switch data.(type) {
+	case *SetHead, *SortedSetHead, *HashHead:
+	default:
+		return data, nil
+	}
+
+	events := []interface{}{data}
+
+	for {
+		event, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+
+		if isKeyDBCollectionEnd(event) {
+			break
+		}
+	}
+
+	expiry, next, err := p.readKeyDBMemberExpiryAux()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		setKeyDBMemberExpiry(event, expiry)
+	}
+
+	if next != nil {
+		events = append(events, next)
+	}
+
+	p.queue = append(p.queue, events[1:]...)
+
+	return events[0], nil
+}
+
+func isKeyDBCollectionEnd(data interface{}) bool {
+	switch data.(type) {
+	case *SetData, *SortedSetData, *HashData:
+		return true
+	}
+
+	return false
+}
+
+// readKeyDBMemberExpiryAux reads the expiries of members following a value.
+// The first aux which is not an expiry is returned as next, and other opcodes
+// are left for the next call of nextLoop.
+func (p *Parser) readKeyDBMemberExpiryAux() (expiry map[string]time.Time, next *Aux, err error) {
+	var member string
+
+	for {
+		opCode, err := p.readOpCode()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read data type: %w", err)
+		}
+
+		if opCode != opCodeAux {
+			p.opCode = &opCode
+
+			return expiry, nil, nil
+		}
+
+		key, err := readString(p.reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read aux key: %w", err)
+		}
+
+		value, err := readString(p.reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read aux value: %w", err)
+		}
+
+		switch key {
+		case keyDBSubExpireKey:
+			member = value
+
+		case keyDBSubExpireWhen:
+			when, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse aux %s: %w", key, err)
+			}
+
+			if expiry == nil {
+				expiry = map[string]time.Time{}
+			}
+
+			expiry[member] = *millisecondsToTime(when)
+
+		default:
+			p.metadata.set(key, value)
+
+			return expiry, &Aux{Key: key, Value: value}, nil
+		}
+	}
+}
+
+func setKeyDBMemberExpiry(data interface{}, expiry map[string]time.Time) {
+	if expiry == nil {
+		return
+	}
+
+	switch v := data.(type) {
+	case *SetEntry:
+		if t, ok := expiry[v.Value]; ok {
+			v.MemberExpiry = timePtr(t)
+		}
+
+	case *SetData:
+		v.MemberExpiry = expiry
+
+	case *SortedSetEntry:
+		if t, ok := expiry[v.Value]; ok {
+			v.MemberExpiry = timePtr(t)
+		}
+
+	case *SortedSetData:
+		v.MemberExpiry = expiry
+
+	case *HashHead:
+		for _, t := range expiry {
+			if v.MinExpiry == nil || t.Before(*v.MinExpiry) {
+				v.MinExpiry = timePtr(t)
+			}
+		}
+
+	case *HashEntry:
+		if t, ok := expiry[v.Index]; ok {
+			v.HashValue.Expiry = timePtr(t)
+		}
+
+	case *HashData:
+		for field, t := range expiry {
+			if _, ok := v.Value[field]; !ok {
+				continue
+			}
+
+			if v.FieldExpiry == nil {
+				v.FieldExpiry = map[string]time.Time{}
+			}
+
+			v.FieldExpiry[field] = t
+
+			if v.MinExpiry == nil || t.Before(*v.MinExpiry) {
+				v.MinExpiry = timePtr(t)
+			}
+		}
+	}
+}
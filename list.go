@@ -79,3 +79,14 @@ func (listZipListValueReader) ReadValue(r byteReader) (interface{}, error) {
 
 	return value, nil
 }
+
+type listListPackValueReader struct{}
+
+func (listListPackValueReader) ReadValue(r byteReader) (interface{}, error) {
+	value, err := readListPackEntry(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list value from listpack: %w", err)
+	}
+
+	return value, nil
+}
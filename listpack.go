@@ -0,0 +1,374 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/tommy351/rdb-go/internal/convert"
+)
+
+const (
+	listPackEnd           = 255
+	listPackUnknownLength = 65535
+)
+
+type listPackIterator struct {
+	DataKey     DataKey
+	Reader      byteReader
+	ValueReader valueReader
+	Mapper      collectionMapper
+	ValueLength int
+
+	buf    byteReader
+	index  int
+	length int
+	done   bool
+	values []interface{}
+}
+
+func (l *listPackIterator) Next() (interface{}, error) {
+	if l.done {
+		return nil, io.EOF
+	}
+
+	if l.buf == nil {
+		buf, err := readStringEncoding(l.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read listpack buffer: %w", err)
+		}
+
+		l.buf = newSliceReader(buf)
+
+		length, err := readListPackHeader(l.buf)
+		if err != nil {
+			return nil, err
+		}
+
+		// The length is not stored in the header when there are too many
+		// entries, so we have to count them.
+		if length == listPackUnknownLength {
+			if length, err = countListPackEntries(buf); err != nil {
+				return nil, err
+			}
+		}
+
+		if length%l.ValueLength != 0 {
+			return nil, ListPackLengthError{
+				Length:      length,
+				ValueLength: l.ValueLength,
+			}
+		}
+
+		l.length = length / l.ValueLength
+
+		return l.Mapper.MapHead(&collectionHead{
+			DataKey: l.DataKey,
+			Length:  l.length,
+		})
+	}
+
+	if l.index == l.length {
+		if err := readListPackEnd(l.buf); err != nil {
+			return nil, err
+		}
+
+		l.done = true
+		l.buf = nil
+
+		return l.Mapper.MapSlice(&collectionSlice{
+			DataKey: l.DataKey,
+			Value:   l.values,
+		})
+	}
+
+	value, err := l.ValueReader.ReadValue(l.buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read value: %w", err)
+	}
+
+	element, err := l.Mapper.MapEntry(&collectionEntry{
+		DataKey: l.DataKey,
+		Index:   l.index,
+		Length:  l.length,
+		Value:   value,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to map entry: %w", err)
+	}
+
+	l.index++
+	l.values = append(l.values, value)
+
+	return element, nil
+}
+
+func countListPackEntries(buf []byte) (int, error) {
+	r := newSliceReader(buf)
+
+	if _, err := readListPackHeader(r); err != nil {
+		return 0, err
+	}
+
+	for count := 0; ; count++ {
+		if r.offset < len(r.data) && r.data[r.offset] == listPackEnd {
+			return count, nil
+		}
+
+		if _, err := readListPackEntry(r); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func readListPackHeader(r byteReader) (int, error) {
+	if _, err := readUint32(r); err != nil {
+		return 0, fmt.Errorf("failed to read listpack total bytes: %w", err)
+	}
+
+	length, err := readUint16(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read listpack length: %w", err)
+	}
+
+	return int(length), nil
+}
+
+func readListPackEnd(r byteReader) error {
+	end, err := readByte(r)
+	if err != nil {
+		return fmt.Errorf("failed to read listpack end: %w", err)
+	}
+
+	if end != listPackEnd {
+		return ListPackEndError{Value: end}
+	}
+
+	return nil
+}
+
+// nolint: gocyclo
+func readListPackEntry(r byteReader) (interface{}, error) {
+	header, err := readByte(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first byte of listpack entry: %w", err)
+	}
+
+	var (
+		value interface{}
+		size  int
+	)
+
+	switch {
+	// 7 bit unsigned integer
+	case header&0x80 == 0:
+		value, size = int64(header&0x7f), 1
+
+	// 6 bit string length
+	case header&0xc0 == 0x80:
+		length := int(header & 0x3f)
+		size = 1 + length
+		value, err = readStringByLength(r, length)
+
+	// 13 bit signed integer
+	case header&0xe0 == 0xc0:
+		next, e := readByte(r)
+		if e != nil {
+			return nil, e
+		}
+
+		v := int64(header&0x1f)<<8 | int64(next)
+		if v >= 1<<12 {
+			v -= 1 << 13
+		}
+
+		value, size = v, 2
+
+	// 12 bit string length
+	case header&0xf0 == 0xe0:
+		next, e := readByte(r)
+		if e != nil {
+			return nil, e
+		}
+
+		length := int(header&0x0f)<<8 | int(next)
+		size = 2 + length
+		value, err = readStringByLength(r, length)
+
+	default:
+		value, size, err = readListPackExtendedEntry(r, header)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the back length of the entry
+	if err := skipBytes(r, listPackBackLenSize(size)); err != nil {
+		return nil, fmt.Errorf("failed to skip listpack entry back length: %w", err)
+	}
+
+	return value, nil
+}
+
+func readListPackExtendedEntry(r byteReader, header byte) (interface{}, int, error) {
+	switch header {
+	// 32 bit string length
+	case 0xf0:
+		length, err := readUint32(r)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		value, err := readStringByLength(r, int(length))
+
+		return value, 5 + int(length), err
+
+	// 16 bit signed integer
+	case 0xf1:
+		value, err := readInt16(r)
+
+		return int64(value), 3, err
+
+	// 24 bit signed integer
+	case 0xf2:
+		value, err := read24BitSignedNumber(r)
+
+		return int64(value), 4, err
+
+	// 32 bit signed integer
+	case 0xf3:
+		value, err := readInt32(r)
+
+		return int64(value), 5, err
+
+	// 64 bit signed integer
+	case 0xf4:
+		value, err := readInt64(r)
+
+		return value, 9, err
+	}
+
+	return nil, 0, ListPackHeaderError{Header: header}
+}
+
+func readListPackInteger(r byteReader) (int64, error) {
+	value, err := readListPackEntry(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return convert.Int64(value)
+}
+
+func readListPackString(r byteReader) (string, error) {
+	value, err := readListPackEntry(r)
+	if err != nil {
+		return "", err
+	}
+
+	return convert.String(value)
+}
+
+func listPackBackLenSize(size int) int {
+	switch {
+	case size <= 127:
+		return 1
+	case size < 16383:
+		return 2
+	case size < 2097151:
+		return 3
+	case size < 268435455:
+		return 4
+	}
+
+	return 5
+}
+
+// encodeListPack returns a listpack containing values. Values are saved as
+// integers when possible.
+func encodeListPack(values []string) []byte {
+	// Total bytes and the number of elements are filled in later
+	buf := make([]byte, 6, 7)
+
+	for _, value := range values {
+		buf = appendListPackEntry(buf, value)
+	}
+
+	buf = append(buf, listPackEnd)
+
+	length := len(values)
+	if length > listPackUnknownLength {
+		length = listPackUnknownLength
+	}
+
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	binary.LittleEndian.PutUint16(buf[4:], uint16(length))
+
+	return buf
+}
+
+func appendListPackEntry(buf []byte, value string) []byte {
+	start := len(buf)
+
+	if v, ok := parseStringInteger(value); ok {
+		buf = appendListPackInteger(buf, v)
+	} else {
+		length := len(value)
+
+		switch {
+		case length < 1<<6:
+			buf = append(buf, 0x80|byte(length))
+		case length < 1<<12:
+			buf = append(buf, 0xe0|byte(length>>8), byte(length))
+		default:
+			buf = appendUint32(append(buf, 0xf0), uint32(length))
+		}
+
+		buf = append(buf, value...)
+	}
+
+	return appendListPackBackLen(buf, uint64(len(buf)-start))
+}
+
+func appendListPackInteger(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v <= 127:
+		return append(buf, byte(v))
+
+	case v >= -(1<<12) && v < 1<<12:
+		u := uint64(v) & (1<<13 - 1)
+
+		return append(buf, 0xc0|byte(u>>8), byte(u))
+
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return appendUint16(append(buf, 0xf1), uint16(v))
+
+	case v >= -(1<<23) && v < 1<<23:
+		return append(buf, 0xf2, byte(v), byte(v>>8), byte(v>>16))
+
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return appendUint32(append(buf, 0xf3), uint32(v))
+	}
+
+	return appendUint64(append(buf, 0xf4), uint64(v))
+}
+
+// appendListPackBackLen appends the length of an entry, which is encoded from
+// right to left so that listpacks can be traversed backwards.
+func appendListPackBackLen(buf []byte, size uint64) []byte {
+	n := listPackBackLenSize(int(size))
+
+	for i := n - 1; i >= 0; i-- {
+		b := byte(size>>(7*uint(i))) & 0x7f
+
+		if i < n-1 {
+			b |= 0x80
+		}
+
+		buf = append(buf, b)
+	}
+
+	return buf
+}
@@ -0,0 +1,569 @@
+package rdb
+
+import (
+	"encoding/json"
+	"math/bits"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Sizes of Redis structures on 64-bit systems.
+const (
+	memPointerSize   = 8
+	memRobjSize      = 16
+	memDictSize      = 56
+	memDictEntrySize = 24
+	memQuickListSize = 40
+	memQuickListNode = 32
+	memZSetSize      = 16
+	memSkipListSize  = 32
+	memStreamSize    = 104
+	memRaxNodeSize   = 48
+	memStreamCGSize  = 64
+	memConsumerSize  = 56
+	memStreamNACK    = 32
+
+	// memEmbStrMaxLength is the maximum length of strings which are allocated
+	// with the object, which are known as embstr.
+	memEmbStrMaxLength = 44
+
+	// memSkipListLevelSize is the size of a level of a skiplist node.
+	memSkipListLevelSize = 16
+
+	// memSkipListNodeSize is the average size of a skiplist node, which
+	// contains the element, the score, the backward pointer and 4/3 levels on
+	// average because each level is promoted with the probability of 1/4.
+	memSkipListNodeSize = 3*memPointerSize + 4*memSkipListLevelSize/3
+
+	// memSkipListMaxLevel is the number of levels of the skiplist header.
+	memSkipListMaxLevel = 32
+
+	// memQuickListNodeBytes is the maximum size of a quicklist node, which is
+	// the default value of list-max-listpack-size. Lists which fit in a node
+	// are saved as a listpack.
+	memQuickListNodeBytes = 8192
+
+	// Default thresholds of Redis 7.2 for compact encodings, which are checked
+	// when values are loaded.
+	memListPackMaxEntries = 128
+	memListPackMaxValue   = 64
+	memIntSetMaxEntries   = 512
+
+	// Approximate sizes of structures of modules.
+	memBloomChainSize     = 32
+	memBloomLinkSize      = 72
+	memCuckooFilterSize   = 64
+	memCuckooSubSize      = 16
+	memTopKSize           = 48
+	memCountMinSketchSize = 32
+	memTDigestSize        = 96
+	memTimeSeriesSize     = 128
+	memTimeSeriesChunk    = 48
+	memTimeSeriesRuleSize = 64
+)
+
+// MemoryUsage contains the estimated memory usage of a key in Redis.
+type MemoryUsage struct {
+	DataKey
+	// Type is one of string, list, set, sortedset, hash, stream and module.
+	Type string
+	// Encoding is the encoding of the value in memory after it is loaded by
+	// Redis 7.2 with the default config, in the same way as OBJECT ENCODING,
+	// e.g. listpack for small hashes saved as ziplists. It is the module name
+	// for values of modules.
+	Encoding string
+	// Size is the estimated number of bytes used by the key, the value and the
+	// expiry.
+	Size              int64
+	NumElements       int
+	LenLargestElement int
+	// ValueUnknown is true when the value is decoded by a module decoder which
+	// is unknown to EstimateMemory, so Size only counts the key.
+	ValueUnknown bool
+}
+
+// EstimateMemory returns the estimated memory usage of a key returned by
+// Parser.Next, which is calculated from the encoding used in memory, the number
+// and the length of elements, and size classes of jemalloc. nil is returned
+// when data is not a key or a whole value, e.g. Aux and ListEntry.
+//
+// Redis converts values on load, so the encoding in memory doesn't depend on
+// the encoding in the file, but on the default thresholds of compact
+// encodings, e.g. hash-max-listpack-entries. Values of modules are estimated
+// from the decoded payload, e.g. bits of bloom filters and the number of
+// chunks of time series.
+func EstimateMemory(data interface{}) *MemoryUsage {
+	switch v := data.(type) {
+	case *StringData:
+		return estimateString(v)
+	case *ListData:
+		return estimateList(v)
+	case *SetData:
+		return estimateSet(v)
+	case *SortedSetData:
+		return estimateSortedSet(v)
+	case *HashData:
+		return estimateHash(v)
+	case *StreamData:
+		return estimateStream(v)
+	case *ModuleData:
+		return estimateModule(v)
+	case *JSONData:
+		return estimateJSON(v)
+	case *BloomFilter:
+		return estimateBloomFilter(v)
+	case *CuckooFilter:
+		return estimateCuckooFilter(v)
+	case *TopK:
+		return estimateTopK(v)
+	case *CountMinSketch:
+		return estimateCountMinSketch(v)
+	case *TDigest:
+		return estimateTDigest(v)
+	case *TimeSeriesData:
+		return estimateTimeSeries(v)
+	case *ListHead, *ListEntry, *SetHead, *SetEntry, *SortedSetHead, *SortedSetEntry,
+		*HashHead, *HashEntry, *StreamHead, *StreamEntry, *TimeSeriesHead, *TimeSeriesEntry, *RawData:
+		return nil
+	}
+
+	// Values decoded by other module decoders
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Struct {
+		if field := value.Elem().FieldByName("DataKey"); field.IsValid() {
+			if key, ok := field.Interface().(DataKey); ok {
+				usage := newMemoryUsage(key, "module", "module")
+				usage.ValueUnknown = true
+
+				return usage
+			}
+		}
+	}
+
+	return nil
+}
+
+func newMemoryUsage(key DataKey, dataType, encoding string) *MemoryUsage {
+	usage := &MemoryUsage{
+		DataKey:  key,
+		Type:     dataType,
+		Encoding: encoding,
+		// The key is saved in the main dictionary with an object
+		Size: memPointerSize + memMallocSize(memDictEntrySize) + memSDSSize(len(key.Key)) + memMallocSize(memRobjSize),
+	}
+
+	if key.Expiry != nil {
+		usage.Size += memPointerSize + memMallocSize(memDictEntrySize)
+	}
+
+	return usage
+}
+
+func (m *MemoryUsage) addElement(length int) {
+	m.NumElements++
+	m.updateLargest(length)
+}
+
+func (m *MemoryUsage) updateLargest(length int) {
+	if length > m.LenLargestElement {
+		m.LenLargestElement = length
+	}
+}
+
+func estimateString(data *StringData) *MemoryUsage {
+	length := len(data.Value)
+
+	if _, ok := parseStringInteger(data.Value); ok {
+		// Integers are saved in the pointer of the object
+		usage := newMemoryUsage(data.DataKey, "string", "int")
+		usage.addElement(length)
+
+		return usage
+	}
+
+	usage := newMemoryUsage(data.DataKey, "string", "raw")
+	usage.addElement(length)
+
+	if length <= memEmbStrMaxLength {
+		usage.Encoding = "embstr"
+		usage.Size += memMallocSize(int64(memRobjSize+3+length+1)) - memMallocSize(memRobjSize)
+	} else {
+		usage.Size += memSDSSize(length)
+	}
+
+	return usage
+}
+
+// memFitsListPack returns true if a value is saved as a listpack by default.
+func memFitsListPack(usage *MemoryUsage) bool {
+	return usage.NumElements <= memListPackMaxEntries && usage.LenLargestElement <= memListPackMaxValue
+}
+
+func estimateList(data *ListData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "list", "quicklist")
+
+	for _, value := range data.Value {
+		usage.addElement(len(value))
+	}
+
+	nodes := splitQuickListNodes(data.Value)
+
+	if len(nodes) == 1 {
+		if size := int64(len(encodeListPack(data.Value))); size <= memQuickListNodeBytes {
+			usage.Encoding = "listpack"
+			usage.Size += memMallocSize(size)
+
+			return usage
+		}
+	}
+
+	usage.Size += memMallocSize(memQuickListSize)
+
+	for _, node := range nodes {
+		usage.Size += memMallocSize(memQuickListNode) + memMallocSize(int64(len(encodeListPack(node))))
+	}
+
+	return usage
+}
+
+// splitQuickListNodes splits values into nodes which are not bigger than
+// memQuickListNodeBytes unless a node contains only one value.
+func splitQuickListNodes(values []string) [][]string {
+	var (
+		nodes [][]string
+		start int
+		size  int
+	)
+
+	for i, value := range values {
+		entrySize := len(appendListPackEntry(nil, value))
+
+		if i > start && size+entrySize > memQuickListNodeBytes {
+			nodes = append(nodes, values[start:i])
+			start, size = i, 0
+		}
+
+		size += entrySize
+	}
+
+	if start < len(values) {
+		nodes = append(nodes, values[start:])
+	}
+
+	return nodes
+}
+
+func estimateSet(data *SetData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "set", "hashtable")
+	ints := make([]int64, 0, len(data.Value))
+
+	for _, value := range data.Value {
+		usage.addElement(len(value))
+
+		if v, ok := parseStringInteger(value); ok {
+			ints = append(ints, v)
+		}
+	}
+
+	switch {
+	case len(ints) == len(data.Value) && len(ints) <= memIntSetMaxEntries:
+		usage.Encoding = "intset"
+
+		sort.Slice(ints, func(i, j int) bool {
+			return ints[i] < ints[j]
+		})
+
+		usage.Size += memMallocSize(int64(len(encodeIntSet(ints))))
+
+	case memFitsListPack(usage):
+		usage.Encoding = "listpack"
+		usage.Size += memMallocSize(int64(len(encodeListPack(data.Value))))
+
+	default:
+		usage.Size += memDictOverhead(len(data.Value))
+
+		for _, value := range data.Value {
+			usage.Size += memSDSSize(len(value))
+		}
+	}
+
+	return usage
+}
+
+func estimateSortedSet(data *SortedSetData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "sortedset", "skiplist")
+	flat := make([]string, 0, len(data.Value)*2)
+
+	for _, value := range data.Value {
+		usage.addElement(len(value.Value))
+		flat = append(flat, value.Value, formatScore(value.Score))
+	}
+
+	if memFitsListPack(usage) {
+		usage.Encoding = "listpack"
+		usage.Size += memMallocSize(int64(len(encodeListPack(flat))))
+
+		return usage
+	}
+
+	usage.Size += memMallocSize(memZSetSize) + memDictOverhead(len(data.Value)) + memMallocSize(memSkipListSize)
+	usage.Size += memMallocSize(3*memPointerSize + memSkipListMaxLevel*memSkipListLevelSize)
+
+	for _, value := range data.Value {
+		usage.Size += memSDSSize(len(value.Value)) + memMallocSize(memSkipListNodeSize)
+	}
+
+	return usage
+}
+
+func estimateHash(data *HashData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "hash", "hashtable")
+	fields := make([]string, 0, len(data.Value))
+
+	for field, value := range data.Value {
+		fields = append(fields, field)
+		usage.addElement(len(field))
+		usage.updateLargest(len(value))
+	}
+
+	sort.Strings(fields)
+
+	if !memFitsListPack(usage) {
+		usage.Size += memDictOverhead(len(fields))
+
+		for _, field := range fields {
+			usage.Size += memSDSSize(len(field)) + memSDSSize(len(data.Value[field]))
+
+			// Fields with TTL are saved with the expiry
+			if _, ok := data.FieldExpiry[field]; ok {
+				usage.Size += memPointerSize
+			}
+		}
+
+		return usage
+	}
+
+	usage.Encoding = "listpack"
+	flat := make([]string, 0, len(fields)*3)
+
+	for _, field := range fields {
+		flat = append(flat, field, data.Value[field])
+
+		// TTLs are saved after values in listpacks of hashes with field expiry
+		if len(data.FieldExpiry) > 0 {
+			var ttl int64 = hashNoTTL
+
+			if expiry, ok := data.FieldExpiry[field]; ok {
+				ttl = timeToMilliseconds(expiry)
+			}
+
+			flat = append(flat, strconv.FormatInt(ttl, 10))
+		}
+	}
+
+	if len(data.FieldExpiry) > 0 {
+		usage.Encoding = "listpackex"
+	}
+
+	usage.Size += memMallocSize(int64(len(encodeListPack(flat))))
+
+	return usage
+}
+
+func estimateStream(data *StreamData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "stream", "stream")
+	usage.Size += memMallocSize(memStreamSize)
+
+	for _, value := range data.Value {
+		usage.NumElements++
+
+		for _, field := range value.Fields {
+			usage.updateLargest(len(field.Value))
+		}
+	}
+
+	for _, node := range splitStreamValues(data.Value, streamNodeEntries) {
+		usage.Size += memMallocSize(memRaxNodeSize) + memMallocSize(int64(len(encodeListPack(streamNodeValues(node)))))
+	}
+
+	for _, group := range data.Groups {
+		usage.Size += memMallocSize(memStreamCGSize) + memSDSSize(len(group.Name))
+		usage.Size += int64(len(group.Pending)) * (memMallocSize(memStreamNACK) + memMallocSize(memRaxNodeSize))
+
+		for _, consumer := range group.Consumers {
+			usage.Size += memMallocSize(memConsumerSize) + memSDSSize(len(consumer.Name))
+			usage.Size += int64(len(consumer.Pending)) * memMallocSize(memRaxNodeSize)
+		}
+	}
+
+	return usage
+}
+
+func estimateModule(data *ModuleData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", data.ModuleName)
+
+	for _, value := range data.Values {
+		usage.NumElements++
+
+		if s, ok := value.Value.(string); ok {
+			usage.Size += memMallocSize(int64(len(s)))
+			usage.updateLargest(len(s))
+		} else {
+			usage.Size += memPointerSize
+		}
+	}
+
+	return usage
+}
+
+// estimateJSON approximates the size of a document with the length of its
+// serialized form.
+func estimateJSON(data *JSONData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisJSONModule)
+
+	switch v := data.Value.(type) {
+	case []interface{}:
+		usage.NumElements = len(v)
+	case map[string]interface{}:
+		usage.NumElements = len(v)
+	default:
+		usage.NumElements = 1
+	}
+
+	buf, err := json.Marshal(data.Value)
+	if err != nil {
+		usage.ValueUnknown = true
+
+		return usage
+	}
+
+	usage.Size += memMallocSize(int64(len(buf)))
+
+	return usage
+}
+
+func estimateBloomFilter(data *BloomFilter) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisBloomBloomFilterModule)
+	usage.Size += memMallocSize(memBloomChainSize)
+
+	for _, filter := range data.Filters {
+		usage.NumElements += int(filter.Size)
+		usage.Size += memMallocSize(memBloomLinkSize) + memMallocSize(int64(len(filter.Data)))
+	}
+
+	return usage
+}
+
+func estimateCuckooFilter(data *CuckooFilter) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisBloomCuckooFilterModule)
+	usage.NumElements = int(data.NumItems)
+	usage.Size += memMallocSize(memCuckooFilterSize)
+
+	for _, filter := range data.Filters {
+		usage.Size += memMallocSize(memCuckooSubSize) + memMallocSize(int64(len(filter.Data)))
+	}
+
+	return usage
+}
+
+func estimateTopK(data *TopK) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisBloomTopKModule)
+	usage.Size += memMallocSize(memTopKSize) + memMallocSize(int64(len(data.Buckets))*topKBucketSize)
+	usage.Size += memMallocSize(int64(data.K) * topKHeapBucketSize)
+
+	for _, item := range data.Items {
+		usage.addElement(len(item.Item))
+		usage.Size += memMallocSize(int64(len(item.Item)) + 1)
+	}
+
+	return usage
+}
+
+func estimateCountMinSketch(data *CountMinSketch) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisBloomCountMinSketchModule)
+	usage.NumElements = len(data.Counters)
+	usage.Size += memMallocSize(memCountMinSketchSize) + memMallocSize(int64(len(data.Counters))*4)
+
+	return usage
+}
+
+func estimateTDigest(data *TDigest) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisBloomTDigestModule)
+	usage.NumElements = len(data.Centroids)
+
+	// Means and weights of centroids are saved in two arrays of the capacity
+	usage.Size += memMallocSize(memTDigestSize) + 2*memMallocSize(data.Capacity*8)
+
+	return usage
+}
+
+func estimateTimeSeries(data *TimeSeriesData) *MemoryUsage {
+	usage := newMemoryUsage(data.DataKey, "module", redisTimeSeriesModule)
+	usage.NumElements = int(data.TotalSamples)
+	usage.Size += memMallocSize(memTimeSeriesSize) + memMallocSize(int64(len(data.SourceKey)))
+
+	// Every chunk allocates ChunkSize bytes for samples
+	chunkSize := memMallocSize(memTimeSeriesChunk) + memMallocSize(int64(data.ChunkSize)) + memMallocSize(memRaxNodeSize)
+	usage.Size += int64(data.ChunkCount) * chunkSize
+
+	for name, value := range data.Labels {
+		usage.Size += memMallocSize(memRobjSize)*2 + memSDSSize(len(name)) + memSDSSize(len(value))
+	}
+
+	for _, rule := range data.Rules {
+		usage.Size += memMallocSize(memTimeSeriesRuleSize) + memSDSSize(len(rule.DestKey))
+	}
+
+	return usage
+}
+
+// memSDSSize returns the size of a SDS string, whose header depends on the
+// length.
+func memSDSSize(length int) int64 {
+	var header int64
+
+	switch size := int64(length); {
+	case size < 1<<5:
+		header = 1
+	case size < 1<<8:
+		header = 3
+	case size < 1<<16:
+		header = 5
+	case size < 1<<32:
+		header = 9
+	default:
+		header = 17
+	}
+
+	return memMallocSize(header + int64(length) + 1)
+}
+
+// memDictOverhead returns the size of a dictionary without keys and values.
+func memDictOverhead(length int) int64 {
+	buckets := int64(4)
+
+	for buckets < int64(length) {
+		buckets *= 2
+	}
+
+	return memMallocSize(memDictSize) + memMallocSize(buckets*memPointerSize) + int64(length)*memMallocSize(memDictEntrySize)
+}
+
+// memMallocSize returns the size class of jemalloc for the given size. Sizes
+// up to 128 bytes are rounded up to multiples of 16, and there are 4 size
+// classes between two powers of two for bigger sizes.
+func memMallocSize(size int64) int64 {
+	switch {
+	case size <= 0:
+		return 0
+	case size <= 8:
+		return 8
+	case size <= 128:
+		return (size + 15) &^ 15
+	}
+
+	base := int64(1) << (63 - bits.LeadingZeros64(uint64(size-1)))
+	step := base / 4
+
+	return (size + step - 1) / step * step
+}
@@ -0,0 +1,204 @@
+package rdb
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EstimateMemory", func() {
+	DescribeTable("memMallocSize",
+		func(size, expected int64) {
+			Expect(memMallocSize(size)).To(Equal(expected))
+		},
+		Entry("zero", int64(0), int64(0)),
+		Entry("tiny", int64(3), int64(8)),
+		Entry("quantum", int64(17), int64(32)),
+		Entry("128", int64(128), int64(128)),
+		Entry("129", int64(129), int64(160)),
+		Entry("257", int64(257), int64(320)),
+		Entry("4097", int64(4097), int64(5120)),
+		Entry("16385", int64(16385), int64(20480)),
+	)
+
+	DescribeTable("memSDSSize",
+		func(length int, expected int64) {
+			Expect(memSDSSize(length)).To(Equal(expected))
+		},
+		Entry("sdshdr5", 5, int64(8)),
+		Entry("sdshdr8", 100, int64(112)),
+		Entry("sdshdr16", 1000, int64(1024)),
+	)
+
+	It("should return nil for events which are not values", func() {
+		Expect(EstimateMemory(&Aux{Key: "a"})).To(BeNil())
+		Expect(EstimateMemory(&ListEntry{})).To(BeNil())
+		Expect(EstimateMemory(&TimeSeriesEntry{})).To(BeNil())
+	})
+
+	It("should estimate strings", func() {
+		integer := EstimateMemory(&StringData{DataKey: DataKey{Key: "a"}, Value: "12345"})
+		Expect(integer.Encoding).To(Equal("int"))
+
+		embstr := EstimateMemory(&StringData{DataKey: DataKey{Key: "a"}, Value: "hello"})
+		Expect(embstr.Encoding).To(Equal("embstr"))
+		Expect(embstr.Size).To(BeNumerically(">", integer.Size))
+
+		raw := EstimateMemory(&StringData{DataKey: DataKey{Key: "a"}, Value: strings.Repeat("a", 100)})
+		Expect(raw.Encoding).To(Equal("raw"))
+		Expect(raw.Size).To(BeNumerically(">=", integer.Size+100))
+		Expect(raw.LenLargestElement).To(Equal(100))
+	})
+
+	It("should count the expiry", func() {
+		key := DataKey{Key: "a"}
+		withoutExpiry := EstimateMemory(&StringData{DataKey: key, Value: "a"})
+
+		key.Expiry = timePtr(time.Now())
+		withExpiry := EstimateMemory(&StringData{DataKey: key, Value: "a"})
+
+		Expect(withExpiry.Size).To(BeNumerically(">", withoutExpiry.Size))
+	})
+
+	DescribeTable("encodings in memory",
+		func(data interface{}, expected string) {
+			Expect(EstimateMemory(data).Encoding).To(Equal(expected))
+		},
+		Entry("small list", &ListData{Value: memTestValues(10, 1)}, "listpack"),
+		Entry("big list", &ListData{Value: memTestValues(1000, 100)}, "quicklist"),
+		Entry("intset", &SetData{Value: memTestIntegers(512)}, "intset"),
+		Entry("intset over max entries", &SetData{Value: memTestIntegers(513)}, "hashtable"),
+		Entry("small set", &SetData{Value: memTestValues(128, 64)}, "listpack"),
+		Entry("set over max entries", &SetData{Value: memTestValues(129, 1)}, "hashtable"),
+		Entry("set over max value", &SetData{Value: memTestValues(1, 65)}, "hashtable"),
+		Entry("small sorted set", &SortedSetData{Value: []SortedSetValue{{Value: "a"}}}, "listpack"),
+		Entry("big sorted set", &SortedSetData{Value: make([]SortedSetValue, 129)}, "skiplist"),
+		Entry("small hash", &HashData{Value: map[string]string{"a": "b"}}, "listpack"),
+		Entry("hash over max value", &HashData{Value: map[string]string{"a": strings.Repeat("b", 65)}}, "hashtable"),
+		Entry("hash with field expiry", &HashData{
+			Value:       map[string]string{"a": "b"},
+			FieldExpiry: map[string]time.Time{"a": time.Unix(1700000000, 0)},
+		}, "listpackex"),
+		Entry("stream", &StreamData{}, "stream"),
+		Entry("json", &JSONData{Value: map[string]interface{}{"a": "b"}}, "ReJSON-RL"),
+	)
+
+	It("should estimate compact encodings smaller than plain ones", func() {
+		small := EstimateMemory(&SetData{Value: memTestValues(128, 1)})
+		big := EstimateMemory(&SetData{Value: memTestValues(129, 1)})
+
+		Expect(small.Encoding).To(Equal("listpack"))
+		Expect(big.Encoding).To(Equal("hashtable"))
+		Expect(big.Size).To(BeNumerically(">", 2*small.Size))
+		Expect(big.NumElements).To(Equal(129))
+		Expect(big.LenLargestElement).To(Equal(4))
+	})
+
+	It("should estimate module values from payloads", func() {
+		key := DataKey{Key: "a"}
+		empty := EstimateMemory(&BloomFilter{DataKey: key})
+		filter := EstimateMemory(&BloomFilter{
+			DataKey: key,
+			Filters: []BloomSubFilter{{Data: make([]byte, 4096), Size: 10}},
+		})
+
+		Expect(filter.Encoding).To(Equal("MBbloom--"))
+		Expect(filter.Size).To(BeNumerically(">=", empty.Size+4096))
+		Expect(filter.NumElements).To(Equal(10))
+
+		// Integers are saved in the object, so only the key is counted
+		keyOnly := EstimateMemory(&StringData{DataKey: key, Value: "1"})
+		json := EstimateMemory(&JSONData{DataKey: key, Value: strings.Repeat("a", 1000)})
+		Expect(json.Size).To(BeNumerically(">=", keyOnly.Size+1000))
+	})
+
+	It("should mark values of unknown modules", func() {
+		type unknownModule struct {
+			DataKey
+		}
+
+		usage := EstimateMemory(&unknownModule{DataKey: DataKey{Key: "a"}})
+		Expect(usage.Type).To(Equal("module"))
+		Expect(usage.ValueUnknown).To(BeTrue())
+	})
+
+	It("should estimate every key of fixtures", func() {
+		for _, name := range []string{
+			"keys_with_expiry",
+			"linkedlist",
+			"quicklist",
+			"intset_64",
+			"regular_sorted_set",
+			"zipmap_that_doesnt_compress",
+			"redis_50_with_streams",
+			"redis_72_with_listpacks",
+			"redis_74_with_hash_field_expiry",
+			"bloom_filter",
+			"cuckoo_filter",
+			"top_k",
+			"count_min_sketch",
+			"t_digest",
+			"redis_json",
+			"redis_time_series",
+			"redis_40_with_module",
+		} {
+			file, err := os.Open("fixtures/" + name + ".rdb")
+			Expect(err).NotTo(HaveOccurred())
+
+			parser := NewParser(file)
+			count := 0
+
+			for {
+				data, err := parser.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+
+				usage := EstimateMemory(data)
+				if usage == nil {
+					continue
+				}
+
+				count++
+				Expect(usage.Size).To(BeNumerically(">", 0))
+				Expect(usage.ValueUnknown).To(BeFalse())
+			}
+
+			Expect(file.Close()).To(Succeed())
+			Expect(count).To(BeNumerically(">", 0), name)
+		}
+	})
+})
+
+func memTestValues(count, length int) []string {
+	values := make([]string, count)
+
+	for i := range values {
+		values[i] = "v" + strconv.Itoa(i)
+
+		if len(values[i]) < length {
+			values[i] += strings.Repeat("a", length-len(values[i]))
+		}
+	}
+
+	return values
+}
+
+func memTestIntegers(count int) []string {
+	values := make([]string, count)
+
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+
+	return values
+}
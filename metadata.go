@@ -0,0 +1,76 @@
+package rdb
+
+import (
+	"strconv"
+	"time"
+)
+
+// Metadata contains well-known aux fields saved by Redis. Fields are left as
+// zero values when they are not saved or can't be parsed. The original values
+// are always available in Aux events.
+type Metadata struct {
+	RedisVersion string
+	RedisBits    int
+	// CreatedAt is the time when the file was created, which is saved as ctime.
+	CreatedAt time.Time
+	// UsedMemory is the number of bytes used by Redis when the file was created.
+	UsedMemory int64
+	// AOFBase is true when the file is the base of a multi-part AOF.
+	AOFBase bool
+	// AOFPreamble is true when the file is the preamble of an AOF.
+	AOFPreamble  bool
+	ReplID       string
+	ReplOffset   int64
+	ReplStreamDB int
+	LuaScripts   []string
+}
+
+// set fills the field of an aux field. Aux fields are informational, so
+// invalid values are ignored instead of failing the parser.
+func (m *Metadata) set(key, value string) {
+	switch key {
+	case "redis-ver":
+		m.RedisVersion = value
+
+	case "redis-bits":
+		if bits, err := strconv.Atoi(value); err == nil {
+			m.RedisBits = bits
+		}
+
+	case "ctime":
+		if ctime, err := strconv.ParseInt(value, 10, 64); err == nil {
+			m.CreatedAt = time.Unix(ctime, 0).UTC()
+		}
+
+	case "used-mem":
+		if usedMem, err := strconv.ParseInt(value, 10, 64); err == nil {
+			m.UsedMemory = usedMem
+		}
+
+	case "aof-base":
+		if aofBase, err := strconv.ParseBool(value); err == nil {
+			m.AOFBase = aofBase
+		}
+
+	case "aof-preamble":
+		if aofPreamble, err := strconv.ParseBool(value); err == nil {
+			m.AOFPreamble = aofPreamble
+		}
+
+	case "repl-id":
+		m.ReplID = value
+
+	case "repl-offset":
+		if offset, err := strconv.ParseInt(value, 10, 64); err == nil {
+			m.ReplOffset = offset
+		}
+
+	case "repl-stream-db":
+		if db, err := strconv.Atoi(value); err == nil {
+			m.ReplStreamDB = db
+		}
+
+	case "lua":
+		m.LuaScripts = append(m.LuaScripts, value)
+	}
+}
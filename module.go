@@ -0,0 +1,423 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	moduleIDNameLength = 9
+	moduleIDCharset    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+	moduleMaxEncodingVersion = 1023
+)
+
+// Values of ModuleAux.When.
+const (
+	ModuleAuxBeforeRDB = 1
+	ModuleAuxAfterRDB  = 2
+)
+
+// ModuleValueType is the type of a value saved by a module.
+type ModuleValueType int
+
+// Types of module values.
+const (
+	ModuleValueSInt   ModuleValueType = rdbModuleOpcodeSInt
+	ModuleValueUInt   ModuleValueType = rdbModuleOpcodeUInt
+	ModuleValueFloat  ModuleValueType = rdbModuleOpcodeFloat
+	ModuleValueDouble ModuleValueType = rdbModuleOpcodeDouble
+	ModuleValueString ModuleValueType = rdbModuleOpcodeString
+)
+
+// ModuleValue is a value saved by a module. The type of Value depends on Type:
+//
+//	ModuleValueSInt: int64
+//	ModuleValueUInt: uint64
+//	ModuleValueFloat: float32
+//	ModuleValueDouble: float64
+//	ModuleValueString: string
+type ModuleValue struct {
+	Type  ModuleValueType
+	Value interface{}
+}
+
+// ModuleDecoder decodes values saved by a module. Decoders are registered
+// with Parser.RegisterModule.
+type ModuleDecoder interface {
+	// DecodeModule reads a value saved with the given encoding version and
+	// returns the data emitted by Parser.Next. The EOF opcode after the value is
+	// read by the parser.
+	DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error)
+}
+
+// moduleIterator is returned by built-in decoders of values which are emitted
+// in several calls of Parser.Next, e.g. chunks of time series. The reader passed
+// to DecodeModule is read by Next until io.EOF is returned.
+type moduleIterator interface {
+	iterator
+	moduleIterator()
+}
+
+// ModuleReader reads values saved by a module. Its methods correspond to the
+// RedisModule_Load* functions of the Redis module API.
+type ModuleReader struct {
+	reader byteReader
+
+	// legacy is true when values are saved without opcodes, which is the case
+	// of the RDB_TYPE_MODULE type used before Redis 5.
+	legacy bool
+}
+
+// Legacy returns true when values are saved without opcodes. In this case,
+// ReadValue always fails because the type of values is unknown.
+func (m *ModuleReader) Legacy() bool {
+	return m.legacy
+}
+
+func (m *ModuleReader) checkOpcode(expected int) error {
+	if m.legacy {
+		return nil
+	}
+
+	return checkRdbModuleOpCode(m.reader, expected)
+}
+
+// ReadUnsigned reads an unsigned integer.
+func (m *ModuleReader) ReadUnsigned() (uint64, error) {
+	if err := m.checkOpcode(rdbModuleOpcodeUInt); err != nil {
+		return 0, err
+	}
+
+	value, err := readLength(m.reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read module unsigned integer: %w", err)
+	}
+
+	return uint64(value), nil
+}
+
+// ReadSigned reads a signed integer. Redis saves signed integers as unsigned
+// integers, so both opcodes are accepted.
+func (m *ModuleReader) ReadSigned() (int64, error) {
+	if !m.legacy {
+		opcode, err := readLength(m.reader)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read module opcode: %w", err)
+		}
+
+		if opcode != rdbModuleOpcodeSInt && opcode != rdbModuleOpcodeUInt {
+			return 0, ModuleOpcodeError{Actual: opcode, Expected: rdbModuleOpcodeSInt}
+		}
+	}
+
+	value, err := readLength(m.reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read module signed integer: %w", err)
+	}
+
+	return int64(value), nil
+}
+
+// ReadFloat reads a float.
+func (m *ModuleReader) ReadFloat() (float32, error) {
+	if err := m.checkOpcode(rdbModuleOpcodeFloat); err != nil {
+		return 0, err
+	}
+
+	value, err := readUint32(m.reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read module float: %w", err)
+	}
+
+	return math.Float32frombits(value), nil
+}
+
+// ReadDouble reads a double.
+func (m *ModuleReader) ReadDouble() (float64, error) {
+	if err := m.checkOpcode(rdbModuleOpcodeDouble); err != nil {
+		return 0, err
+	}
+
+	value, err := readBinaryDouble(m.reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read module double: %w", err)
+	}
+
+	return value, nil
+}
+
+// ReadString reads a string.
+func (m *ModuleReader) ReadString() (string, error) {
+	if err := m.checkOpcode(rdbModuleOpcodeString); err != nil {
+		return "", err
+	}
+
+	value, err := readString(m.reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read module string: %w", err)
+	}
+
+	return value, nil
+}
+
+// ReadValue reads a value of any type. ErrUntypedModuleValue is returned when
+// values are saved without opcodes.
+func (m *ModuleReader) ReadValue() (ModuleValue, error) {
+	if m.legacy {
+		return ModuleValue{}, ErrUntypedModuleValue
+	}
+
+	opcode, err := readLength(m.reader)
+	if err != nil {
+		return ModuleValue{}, fmt.Errorf("failed to read module opcode: %w", err)
+	}
+
+	if opcode == rdbModuleOpcodeEOF {
+		return ModuleValue{}, fmt.Errorf("unexpected module EOF: %w", io.ErrUnexpectedEOF)
+	}
+
+	return readModuleValue(m.reader, opcode)
+}
+
+// ModuleAux contains auxiliary data saved by a module. When is either
+// ModuleAuxBeforeRDB or ModuleAuxAfterRDB.
+type ModuleAux struct {
+	ModuleName      string
+	EncodingVersion int
+	When            int
+	Values          []ModuleValue
+}
+
+// DecodeModuleID returns the module name and the encoding version encoded in
+// a module type ID. The name is 9 characters long and the encoding version is
+// stored in the lowest 10 bits.
+//
+// https://github.com/redis/redis/blob/24187ed8e396625cc44a6bbeeb87e01aec55c27d/src/module.c#L6599-L6615
+func DecodeModuleID(id uint64) (string, int) {
+	name := make([]byte, moduleIDNameLength)
+
+	for i := range name {
+		shift := 64 - 6*(i+1)
+		name[i] = moduleIDCharset[(id>>shift)&63]
+	}
+
+	return string(name), int(id & moduleMaxEncodingVersion)
+}
+
+// EncodeModuleID returns the module type ID of the given module name and
+// encoding version. It is the inverse of DecodeModuleID.
+func EncodeModuleID(name string, encodingVersion int) (uint64, error) {
+	if len(name) != moduleIDNameLength {
+		return 0, InvalidModuleNameError{Name: name}
+	}
+
+	if encodingVersion < 0 || encodingVersion > moduleMaxEncodingVersion {
+		return 0, InvalidModuleEncodingVersionError{EncodingVersion: encodingVersion}
+	}
+
+	var id uint64
+
+	for i := 0; i < len(name); i++ {
+		index := strings.IndexByte(moduleIDCharset, name[i])
+		if index < 0 {
+			return 0, InvalidModuleNameError{Name: name}
+		}
+
+		id = id<<6 | uint64(index)
+	}
+
+	return id<<10 | uint64(encodingVersion), nil
+}
+
+func readModuleID(r byteReader) (uint64, error) {
+	id, err := readLength(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read module ID: %w", err)
+	}
+
+	return uint64(id), nil
+}
+
+func readModuleAux(r byteReader) (*ModuleAux, error) {
+	id, err := readModuleID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	aux := new(ModuleAux)
+	aux.ModuleName, aux.EncodingVersion = DecodeModuleID(id)
+
+	when, err := (&ModuleReader{reader: r}).ReadUnsigned()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module aux when: %w", err)
+	}
+
+	aux.When = int(when)
+
+	if aux.Values, err = readModuleValues(r); err != nil {
+		return nil, err
+	}
+
+	return aux, nil
+}
+
+// readModuleValues reads module values until the EOF opcode.
+func readModuleValues(r byteReader) ([]ModuleValue, error) {
+	var values []ModuleValue
+
+	for {
+		opcode, err := readLength(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module opcode: %w", err)
+		}
+
+		if opcode == rdbModuleOpcodeEOF {
+			return values, nil
+		}
+
+		value, err := readModuleValue(r, opcode)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+}
+
+func readModuleValue(r byteReader, opcode int) (ModuleValue, error) {
+	value := ModuleValue{Type: ModuleValueType(opcode)}
+
+	switch opcode {
+	case rdbModuleOpcodeSInt:
+		v, err := readLength(r)
+		if err != nil {
+			return value, fmt.Errorf("failed to read module signed integer: %w", err)
+		}
+
+		value.Value = int64(v)
+
+	case rdbModuleOpcodeUInt:
+		v, err := readLength(r)
+		if err != nil {
+			return value, fmt.Errorf("failed to read module unsigned integer: %w", err)
+		}
+
+		value.Value = uint64(v)
+
+	case rdbModuleOpcodeFloat:
+		v, err := readUint32(r)
+		if err != nil {
+			return value, fmt.Errorf("failed to read module float: %w", err)
+		}
+
+		value.Value = math.Float32frombits(v)
+
+	case rdbModuleOpcodeDouble:
+		v, err := readBinaryDouble(r)
+		if err != nil {
+			return value, fmt.Errorf("failed to read module double: %w", err)
+		}
+
+		value.Value = v
+
+	case rdbModuleOpcodeString:
+		v, err := readString(r)
+		if err != nil {
+			return value, fmt.Errorf("failed to read module string: %w", err)
+		}
+
+		value.Value = v
+
+	default:
+		return value, ModuleOpcodeError{Actual: opcode, Expected: rdbModuleOpcodeEOF}
+	}
+
+	return value, nil
+}
+
+// ModuleData contains the values saved by a module which is not decoded by
+// any of the built-in decoders.
+type ModuleData struct {
+	DataKey
+	ModuleName      string
+	EncodingVersion int
+	Values          []ModuleValue
+}
+
+func readModuleData(r byteReader, key DataKey, id uint64) (*ModuleData, error) {
+	data := &ModuleData{DataKey: key}
+	data.ModuleName, data.EncodingVersion = DecodeModuleID(id)
+
+	values, err := readModuleValues(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values of module %s: %w", data.ModuleName, err)
+	}
+
+	data.Values = values
+
+	return data, nil
+}
+
+// skipModuleValues skips module values until the EOF opcode.
+func skipModuleValues(r byteReader) error {
+	for {
+		opcode, err := readLength(r)
+		if err != nil {
+			return fmt.Errorf("failed to read module opcode: %w", err)
+		}
+
+		switch opcode {
+		case rdbModuleOpcodeEOF:
+			return nil
+
+		case rdbModuleOpcodeSInt, rdbModuleOpcodeUInt:
+			_, err = readLength(r)
+
+		case rdbModuleOpcodeFloat:
+			err = skipBytes(r, 4)
+
+		case rdbModuleOpcodeDouble:
+			err = skipBinaryDouble(r)
+
+		case rdbModuleOpcodeString:
+			err = skipString(r)
+
+		default:
+			return ModuleOpcodeError{Actual: opcode, Expected: rdbModuleOpcodeEOF}
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to skip module value: %w", err)
+		}
+	}
+}
+
+// moduleEOFIterator reads the EOF opcode after all values of a moduleIterator
+// are read.
+type moduleEOFIterator struct {
+	Reader     byteReader
+	ModuleName string
+	Iterator   moduleIterator
+}
+
+func (m *moduleEOFIterator) Next() (interface{}, error) {
+	data, err := m.Iterator.Next()
+
+	if errors.Is(err, io.EOF) {
+		if err := checkRdbModuleOpCode(m.Reader, rdbModuleOpcodeEOF); err != nil {
+			return nil, fmt.Errorf("failed to read module %s EOF: %w", m.ModuleName, err)
+		}
+
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode module %s: %w", m.ModuleName, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package rdb
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ModuleID", func() {
+	DescribeTable("DecodeModuleID", func(id uint64, name string, encodingVersion int) {
+		actualName, actualEncodingVersion := DecodeModuleID(id)
+		Expect(actualName).To(Equal(name))
+		Expect(actualEncodingVersion).To(Equal(encodingVersion))
+	},
+		Entry("MBbloom--", uint64(3465209449566631940), "MBbloom--", 4),
+		Entry("MBbloomCF", uint64(3465209449562641412), "MBbloomCF", 4),
+		Entry("TopK-TYPE", uint64(5659418315958718464), "TopK-TYPE", 0),
+		Entry("TDIS-TYPE", uint64(5490471757281169408), "TDIS-TYPE", 0),
+		Entry("CMSk-TYPE", uint64(631811237999480832), "CMSk-TYPE", 0),
+	)
+
+	DescribeTable("EncodeModuleID", func(name string, encodingVersion int, expected uint64) {
+		Expect(EncodeModuleID(name, encodingVersion)).To(Equal(expected))
+	},
+		Entry("MBbloom--", "MBbloom--", 4, uint64(3465209449566631940)),
+		Entry("MBbloomCF", "MBbloomCF", 4, uint64(3465209449562641412)),
+		Entry("max encoding version", "test__rdb", 1023, uint64(0xb5eb2dfffadd6fff)),
+	)
+
+	DescribeTable("EncodeModuleID errors", func(name string, encodingVersion int, expected error) {
+		_, err := EncodeModuleID(name, encodingVersion)
+		Expect(err).To(Equal(expected))
+	},
+		Entry("name too short", "MBbloom", 4, InvalidModuleNameError{Name: "MBbloom"}),
+		Entry("name too long", "MBbloom---", 4, InvalidModuleNameError{Name: "MBbloom---"}),
+		Entry("invalid character", "MBbloom.-", 4, InvalidModuleNameError{Name: "MBbloom.-"}),
+		Entry("negative encoding version", "MBbloom--", -1, InvalidModuleEncodingVersionError{EncodingVersion: -1}),
+		Entry("encoding version too large", "MBbloom--", 1024, InvalidModuleEncodingVersionError{EncodingVersion: 1024}),
+	)
+})
+
+var _ = Describe("UnsupportedDataTypeError", func() {
+	DescribeTable("Error", func(err UnsupportedDataTypeError, expected string) {
+		Expect(err.Error()).To(Equal(expected))
+	},
+		Entry("data type", UnsupportedDataTypeError{DataType: 100}, "unsupported data type 100"),
+		Entry("version", UnsupportedDataTypeError{
+			DataType: typeSetListPack,
+			Version:  10,
+		}, "data type 20 is not supported in RDB version 10"),
+		Entry("module", UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      "MBbloom--",
+			EncodingVersion: 2,
+		}, "unsupported module MBbloom-- encver 2"),
+	)
+})
@@ -16,31 +16,44 @@ const (
 	len64Bit  = 0x81
 	lenEncVal = 3
 
-	opCodeModuleAux    = 247
-	opCodeIdle         = 248
-	opCodeFreq         = 249
-	opCodeAux          = 250
-	opCodeResizeDB     = 251
-	opCodeExpireTimeMS = 252
-	opCodeExpireTime   = 253
-	opCodeSelectDB     = 254
-	opCodeEOF          = 255
-
-	typeString          = 0
-	typeList            = 1
-	typeSet             = 2
-	typeZSet            = 3
-	typeHash            = 4
-	typeZSet2           = 5
-	typeModule          = 6
-	typeModule2         = 7
-	typeHashZipMap      = 9
-	typeListZipList     = 10
-	typeSetIntSet       = 11
-	typeZSetZipList     = 12
-	typeHashZipList     = 13
-	typeListQuickList   = 14
-	typeStreamListPacks = 15
+	opCodeSlotInfo      = 244
+	opCodeFunction2     = 245
+	opCodeFunctionPreGA = 246
+	opCodeModuleAux     = 247
+	opCodeIdle          = 248
+	opCodeFreq          = 249
+	opCodeAux           = 250
+	opCodeResizeDB      = 251
+	opCodeExpireTimeMS  = 252
+	opCodeExpireTime    = 253
+	opCodeSelectDB      = 254
+	opCodeEOF           = 255
+
+	typeString              = 0
+	typeList                = 1
+	typeSet                 = 2
+	typeZSet                = 3
+	typeHash                = 4
+	typeZSet2               = 5
+	typeModule              = 6
+	typeModule2             = 7
+	typeHashZipMap          = 9
+	typeListZipList         = 10
+	typeSetIntSet           = 11
+	typeZSetZipList         = 12
+	typeHashZipList         = 13
+	typeListQuickList       = 14
+	typeStreamListPacks     = 15
+	typeHashListPack        = 16
+	typeZSetListPack        = 17
+	typeListQuickList2      = 18
+	typeStreamListPacks2    = 19
+	typeSetListPack         = 20
+	typeStreamListPacks3    = 21
+	typeHashMetadataPreGA   = 22
+	typeHashListPackExPreGA = 23
+	typeHashMetadata        = 24
+	typeHashListPackEx      = 25
 
 	encInt8  = 0
 	encInt16 = 1
@@ -48,7 +61,10 @@ const (
 	encLZF   = 3
 
 	minVersion = 1
-	maxVersion = 9
+	maxVersion = 12
+
+	// The checksum is added to the end of RDB files since version 5.
+	minChecksumVersion = 5
 
 	rdbModuleOpcodeEOF    = 0
 	rdbModuleOpcodeSInt   = 1
@@ -56,57 +72,216 @@ const (
 	rdbModuleOpcodeFloat  = 3
 	rdbModuleOpcodeDouble = 4
 	rdbModuleOpcodeString = 5
-
-	// module ID is encoded using given module name
-	// https://github.com/redis/redis/blob/24187ed8e396625cc44a6bbeeb87e01aec55c27d/src/module.c#L6599-L6615
-	redisBloomBloomFilter    = 3465209449566631940 // MBbloom--
-	redisBloomCuckooFilter   = 3465209449562641412 // MBbloomCF
-	redisBloomTopK           = 5659418315958718464 // TopK-TYPE
-	redisBloomTDigest        = 5490471757281169408 // TDIS-TYPE
-	redisBloomCountMinSketch = 631811237999480832  // CMSk-TYPE
 )
 
 // nolint: gochecknoglobals
 var (
 	magicString     = []byte("REDIS")
 	errContinueLoop = errors.New("continue loop")
+
+	// opCodeMinVersions contains the first RDB version of opcodes. Values of
+	// these opcodes are data types in older versions, which don't exist.
+	opCodeMinVersions = map[byte]int{
+		opCodeModuleAux:     9,
+		opCodeIdle:          9,
+		opCodeFreq:          9,
+		opCodeFunctionPreGA: 10,
+		opCodeFunction2:     10,
+		opCodeSlotInfo:      12,
+	}
+
+	// dataTypeMinVersions contains the first RDB version of data types which
+	// don't exist since version 1.
+	dataTypeMinVersions = map[byte]int{
+		typeZSet2:               8,
+		typeModule:              8,
+		typeModule2:             8,
+		typeListQuickList:       7,
+		typeStreamListPacks:     9,
+		typeHashListPack:        10,
+		typeZSetListPack:        10,
+		typeListQuickList2:      10,
+		typeStreamListPacks2:    10,
+		typeSetListPack:         11,
+		typeStreamListPacks3:    11,
+		typeHashMetadataPreGA:   12,
+		typeHashListPackExPreGA: 12,
+		typeHashMetadata:        12,
+		typeHashListPackEx:      12,
+	}
+
+	// dataTypeEncodings contains encodings of data types which are not saved
+	// as EncodingPlain.
+	dataTypeEncodings = map[byte]Encoding{
+		typeHashZipMap:          EncodingZipMap,
+		typeListZipList:         EncodingZipList,
+		typeSetIntSet:           EncodingIntSet,
+		typeZSetZipList:         EncodingZipList,
+		typeHashZipList:         EncodingZipList,
+		typeListQuickList:       EncodingQuickList,
+		typeStreamListPacks:     EncodingListPack,
+		typeHashListPack:        EncodingListPack,
+		typeZSetListPack:        EncodingListPack,
+		typeListQuickList2:      EncodingQuickList,
+		typeStreamListPacks2:    EncodingListPack,
+		typeSetListPack:         EncodingListPack,
+		typeStreamListPacks3:    EncodingListPack,
+		typeHashListPackExPreGA: EncodingListPack,
+		typeHashListPackEx:      EncodingListPack,
+	}
 )
 
 // Parser parses a RDB dump file.
 type Parser struct {
 	KeyFilter func(key *DataKey) bool
 
+	// HashFieldFilter is called for each field of a hash. The field is excluded
+	// from HashEntry and HashData when it returns false. HashHead is returned
+	// before fields are read, so Length and MinExpiry of HashHead and Length of
+	// HashEntry still count excluded fields.
+	HashFieldFilter func(key *DataKey, field *HashValue) bool
+
+	// SkipChecksum disables the verification of the CRC64 checksum at the end of
+	// the file. It must be set before the first call of Next.
+	SkipChecksum bool
+
+	// KeyDB enables the KeyDB dialect, which attaches expiries of members set
+	// by EXPIREMEMBER to SetEntry, SortedSetEntry and HashEntry. Events of sets,
+	// sorted sets and hashes are buffered until all entries are read because
+	// expiries of members are saved after the value. It must be set before the
+	// first call of Next.
+	KeyDB bool
+
+	// RawValues returns values as RawData without decoding them, which can be
+	// restored with the RESTORE command after being wrapped by DumpPayload.
+	RawValues bool
+
+	source      *bufferReader
 	reader      byteReader
+	checksum    *checksumReader
+	modules     map[string]ModuleDecoder
 	initialized bool
+	version     int
 	db          int
+	slot        int
+	metadata    Metadata
 	expiry      *time.Time
+	idle        *time.Duration
+	freq        *uint8
 	dataType    *byte
+	encoding    Encoding
 	key         string
 	iterator    iterator
+
+	// opCode is read ahead by the KeyDB dialect.
+	opCode *byte
+	// queue contains events buffered by the KeyDB dialect.
+	queue []interface{}
 }
 
 // NewParser returns a new Parser to read from r.
 func NewParser(r io.Reader) *Parser {
+	source := newBufferReader(r)
+
 	return &Parser{
-		reader: newBufferReader(r),
+		source: source,
+		reader: source,
 		db:     -1,
+		slot:   -1,
+		modules: map[string]ModuleDecoder{
+			redisBloomBloomFilterModule:    bloomFilterDecoder{},
+			redisBloomCuckooFilterModule:   cuckooFilterDecoder{},
+			redisBloomTopKModule:           topKDecoder{},
+			redisBloomTDigestModule:        tDigestDecoder{},
+			redisBloomCountMinSketchModule: countMinSketchDecoder{},
+			redisJSONModule:                jsonDecoder{},
+			redisTimeSeriesModule:          timeSeriesDecoder{},
+		},
 	}
 }
 
+// RegisterModule registers a decoder for values of the module with the given
+// name. The name is the 9-character name used when the module type was created
+// in Redis, e.g. "MBbloom--". Values of modules without a decoder are returned
+// as ModuleData, except values of the legacy RDB_TYPE_MODULE type, which fail
+// with UnsupportedLegacyModuleError.
+func (p *Parser) RegisterModule(name string, decoder ModuleDecoder) {
+	p.modules[name] = decoder
+}
+
+// Metadata returns well-known aux fields read so far. Aux fields are saved
+// before databases, so Metadata is complete once the first DatabaseSize or
+// key is returned by Next.
+func (p *Parser) Metadata() Metadata {
+	return p.metadata
+}
+
+// Slot returns the cluster slot of the current key, which is read from the
+// last SlotInfo of the current database. -1 is returned when the slot is
+// unknown.
+func (p *Parser) Slot() int {
+	return p.slot
+}
+
+// Version returns the version of the RDB file. It returns 0 before the first
+// call of Next.
+func (p *Parser) Version() int {
+	return p.version
+}
+
+// Encoding returns the encoding of the last key returned by Next as saved in
+// the file. Lists saved as quicklists of listpacks are EncodingQuickList, and
+// streams are EncodingListPack.
+func (p *Parser) Encoding() Encoding {
+	return p.encoding
+}
+
 // Next reads data from the reader until the next token and returns one of the
 // following types:
 //
+//	*Header
 //	*Aux
+//	*ModuleAux
+//	*FunctionLibrary
 //	*DatabaseSize
+//	*SlotInfo
 //	*StringData
 //	*ListHead, *ListEntry, *ListData
 //	*SetHead, *SetEntry, *SetData
 //	*SortedSetHead, *SortedSetEntry, *SortedSetData
 //	*MapHead, *MapEntry, *MapData
+//	*StreamHead, *StreamEntry, *StreamData
+//	*BloomFilter, *CuckooFilter, *TopK, *TDigest, *CountMinSketch
+//	*JSONData
+//	*TimeSeriesHead, *TimeSeriesEntry, *TimeSeriesData
+//	*ModuleData
+//	*RawData
 //
-// Next returns a io.EOF error when a EOF token is read.
+// Header is always returned first. Next returns a io.EOF error when a EOF token
+// is read.
 func (p *Parser) Next() (interface{}, error) {
+	if len(p.queue) > 0 {
+		data := p.queue[0]
+		p.queue = p.queue[1:]
+
+		return data, nil
+	}
+
+	data, err := p.next()
+	if err != nil || !p.KeyDB {
+		return data, err
+	}
+
+	return p.readKeyDBMemberExpiry(data)
+}
+
+func (p *Parser) next() (interface{}, error) {
 	if !p.initialized {
+		if !p.SkipChecksum {
+			p.checksum = &checksumReader{reader: p.reader}
+			p.reader = p.checksum
+		}
+
 		if err := p.verifyMagicString(); err != nil {
 			return nil, err
 		}
@@ -116,9 +291,13 @@ func (p *Parser) Next() (interface{}, error) {
 		}
 
 		p.initialized = true
+
+		return &Header{Version: p.version}, nil
 	}
 
 	p.expiry = nil
+	p.idle = nil
+	p.freq = nil
 
 	for {
 		data, err := p.nextLoop()
@@ -140,6 +319,17 @@ func (p *Parser) Next() (interface{}, error) {
 	return nil, io.EOF
 }
 
+func (p *Parser) readOpCode() (byte, error) {
+	if p.opCode != nil {
+		opCode := *p.opCode
+		p.opCode = nil
+
+		return opCode, nil
+	}
+
+	return readByte(p.reader)
+}
+
 func (p *Parser) verifyMagicString() error {
 	buf, err := p.reader.ReadBytes(len(magicString))
 	if err != nil {
@@ -168,6 +358,32 @@ func (p *Parser) verifyVersion() error {
 		return UnsupportedVersionError{Version: version}
 	}
 
+	p.version = version
+
+	return nil
+}
+
+func (p *Parser) verifyChecksum() error {
+	if p.checksum == nil || p.version < minChecksumVersion {
+		return nil
+	}
+
+	actual := p.checksum.checksum
+
+	expected, err := readUint64(p.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	// Checksum is disabled when it is zero
+	if expected == 0 {
+		return nil
+	}
+
+	if expected != actual {
+		return ChecksumMismatchError{Expected: expected, Actual: actual}
+	}
+
 	return nil
 }
 
@@ -181,11 +397,19 @@ func (p *Parser) nextLoop() (interface{}, error) {
 		return data, nil
 	}
 
-	dataType, err := readByte(p.reader)
+	dataType, err := p.readOpCode()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data type: %w", err)
 	}
 
+	if version, ok := opCodeMinVersions[dataType]; ok && p.version < version {
+		return nil, UnsupportedOpCodeError{OpCode: dataType, Version: p.version}
+	}
+
+	if version, ok := dataTypeMinVersions[dataType]; ok && p.version < version {
+		return nil, UnsupportedDataTypeError{DataType: dataType, Version: p.version}
+	}
+
 	switch dataType {
 	case opCodeExpireTimeMS:
 		if p.expiry, err = readMillisecondsTime(p.reader); err != nil {
@@ -202,17 +426,23 @@ func (p *Parser) nextLoop() (interface{}, error) {
 		return nil, errContinueLoop
 
 	case opCodeIdle:
-		if _, err := readLength(p.reader); err != nil {
+		idle, err := readLength(p.reader)
+		if err != nil {
 			return nil, fmt.Errorf("failed to read idle: %w", err)
 		}
 
+		p.idle = durationPtr(time.Duration(idle) * time.Second)
+
 		return nil, errContinueLoop
 
 	case opCodeFreq:
-		if _, err := readByte(p.reader); err != nil {
+		freq, err := readByte(p.reader)
+		if err != nil {
 			return nil, fmt.Errorf("failed to read freq: %w", err)
 		}
 
+		p.freq = &freq
+
 		return nil, errContinueLoop
 
 	case opCodeSelectDB:
@@ -220,6 +450,8 @@ func (p *Parser) nextLoop() (interface{}, error) {
 			return nil, fmt.Errorf("failed to read database selector: %w", err)
 		}
 
+		p.slot = -1
+
 		return nil, errContinueLoop
 
 	case opCodeAux:
@@ -233,6 +465,14 @@ func (p *Parser) nextLoop() (interface{}, error) {
 			return nil, fmt.Errorf("failed to read aux value: %w", err)
 		}
 
+		// Expiries of members are attached to entries by readKeyDBMemberExpiry,
+		// so the rest are dropped, e.g. expiries of members of filtered keys.
+		if p.KeyDB && isKeyDBMemberExpiryAux(key) {
+			return nil, errContinueLoop
+		}
+
+		p.metadata.set(key, value)
+
 		return &Aux{Key: key, Value: value}, nil
 
 	case opCodeResizeDB:
@@ -251,11 +491,48 @@ func (p *Parser) nextLoop() (interface{}, error) {
 			Expire: expireSize,
 		}, nil
 
+	case opCodeSlotInfo:
+		info := &SlotInfo{}
+
+		for _, field := range []*int{&info.ID, &info.Size, &info.ExpiresSize} {
+			if *field, err = readLength(p.reader); err != nil {
+				return nil, fmt.Errorf("failed to read slot info: %w", err)
+			}
+		}
+
+		p.slot = info.ID
+
+		return info, nil
+
 	case opCodeModuleAux:
-		// TODO
+		aux, err := readModuleAux(p.reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module aux: %w", err)
+		}
+
+		return aux, nil
+
+	case opCodeFunction2:
+		lib, err := readFunction2(p.reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read function: %w", err)
+		}
+
+		return lib, nil
+
+	case opCodeFunctionPreGA:
+		lib, err := readFunctionPreGA(p.reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read function: %w", err)
+		}
+
+		return lib, nil
 
 	case opCodeEOF:
-		// TODO: verify checksum
+		if err := p.verifyChecksum(); err != nil {
+			return nil, err
+		}
+
 		return nil, io.EOF
 	}
 
@@ -264,6 +541,7 @@ func (p *Parser) nextLoop() (interface{}, error) {
 	}
 
 	p.dataType = &dataType
+	p.encoding = dataTypeEncodings[dataType]
 
 	return nil, errContinueLoop
 }
@@ -272,6 +550,8 @@ func (p *Parser) readData() (interface{}, error) {
 	key := DataKey{
 		Key:      p.key,
 		Expiry:   p.expiry,
+		Idle:     p.idle,
+		Freq:     p.freq,
 		Database: p.db,
 	}
 
@@ -282,16 +562,24 @@ func (p *Parser) readData() (interface{}, error) {
 
 		p.dataType = nil
 		p.expiry = nil
+		p.idle = nil
+		p.freq = nil
 
 		return nil, errContinueLoop
 	}
 
+	if p.RawValues {
+		return p.readRawData(key)
+	}
+
 	if p.iterator != nil {
 		value, err := p.iterator.Next()
 
 		if errors.Is(err, io.EOF) {
 			p.dataType = nil
 			p.expiry = nil
+			p.idle = nil
+			p.freq = nil
 			p.iterator = nil
 
 			return nil, errContinueLoop
@@ -301,6 +589,10 @@ func (p *Parser) readData() (interface{}, error) {
 			return nil, fmt.Errorf("iterator error: %w", err)
 		}
 
+		if p.HashFieldFilter != nil {
+			return p.filterHashFields(value)
+		}
+
 		return value, nil
 	}
 
@@ -406,6 +698,37 @@ func (p *Parser) readData() (interface{}, error) {
 
 		return nil, errContinueLoop
 
+	case typeHashMetadataPreGA, typeHashMetadata:
+		minExpiry, err := p.readHashMinExpiry()
+		if err != nil {
+			return nil, err
+		}
+
+		p.iterator = &seqIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: hashMetadataValueReader{MinExpiry: minExpiry},
+			Mapper:      hashMapper{MinExpiry: minExpiry},
+		}
+
+		return nil, errContinueLoop
+
+	case typeHashListPackExPreGA, typeHashListPackEx:
+		minExpiry, err := p.readHashMinExpiry()
+		if err != nil {
+			return nil, err
+		}
+
+		p.iterator = &listPackIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: hashListPackExValueReader{},
+			Mapper:      hashMapper{MinExpiry: minExpiry},
+			ValueLength: 3,
+		}
+
+		return nil, errContinueLoop
+
 	case typeListQuickList:
 		p.iterator = &quickListIterator{
 			DataKey:     key,
@@ -416,34 +739,78 @@ func (p *Parser) readData() (interface{}, error) {
 
 		return nil, errContinueLoop
 
-	case typeModule2:
-		length, err := readLength(p.reader)
+	case typeListQuickList2:
+		p.iterator = &quickListIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: listListPackValueReader{},
+			Mapper:      listMapper{},
+			ListPack:    true,
+		}
+
+		return nil, errContinueLoop
+
+	case typeHashListPack:
+		p.iterator = &listPackIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: hashListPackValueReader{},
+			Mapper:      hashMapper{},
+			ValueLength: 2,
+		}
+
+		return nil, errContinueLoop
+
+	case typeZSetListPack:
+		p.iterator = &listPackIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: sortedSetListPackValueReader{},
+			Mapper:      sortedSetMapper{},
+			ValueLength: 2,
+		}
+
+		return nil, errContinueLoop
+
+	case typeSetListPack:
+		p.iterator = &listPackIterator{
+			DataKey:     key,
+			Reader:      p.reader,
+			ValueReader: setListPackValueReader{},
+			Mapper:      setMapper{},
+			ValueLength: 1,
+		}
+
+		return nil, errContinueLoop
+
+	case typeStreamListPacks, typeStreamListPacks2, typeStreamListPacks3:
+		p.iterator = &streamIterator{
+			DataKey:  key,
+			Reader:   p.reader,
+			DataType: *p.dataType,
+		}
+
+		return nil, errContinueLoop
+
+	case typeModule:
+		offset := p.source.Position()
+
+		id, err := readModuleID(p.reader)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read module length: %w", err)
+			return nil, err
 		}
 
-		dataType := *p.dataType
 		p.dataType = nil
 
-		switch length {
-		case redisBloomBloomFilter:
-			if err := readBloomFilter(p.reader); err != nil {
-				return nil, err
-			}
+		return p.readLegacyModule(key, id, offset)
 
-			return &BloomFilter{key}, nil
-		case redisBloomCuckooFilter:
-			if err := readCuckooFilter(p.reader); err != nil {
-				return nil, err
-			}
-
-			return &CuckooFilter{key}, nil
-		case redisBloomTopK, redisBloomTDigest, redisBloomCountMinSketch:
-			// TODO
-			return nil, UnsupportedDataTypeError{DataType: dataType}
-		default: // other data types beside redisbloom
-			return nil, UnsupportedDataTypeError{DataType: dataType}
+	case typeModule2:
+		id, err := readModuleID(p.reader)
+		if err != nil {
+			return nil, err
 		}
+
+		return p.readModule(key, id)
 	}
 
 	return nil, UnsupportedDataTypeError{DataType: *p.dataType}
@@ -452,7 +819,8 @@ func (p *Parser) readData() (interface{}, error) {
 // nolint: gocognit
 func (p *Parser) skipData() error {
 	switch *p.dataType {
-	case typeString, typeHashZipMap, typeListZipList, typeSetIntSet, typeZSetZipList, typeHashZipList:
+	case typeString, typeHashZipMap, typeListZipList, typeSetIntSet, typeZSetZipList, typeHashZipList,
+		typeHashListPack, typeZSetListPack, typeSetListPack:
 		return p.skipStrings(1)
 
 	case typeList, typeSet:
@@ -501,188 +869,212 @@ func (p *Parser) skipData() error {
 
 		return p.skipStrings(length)
 
-	case typeModule:
-		// TODO
+	case typeHashMetadataPreGA, typeHashMetadata:
+		if _, err := p.readHashMinExpiry(); err != nil {
+			return err
+		}
 
-	case typeModule2:
 		length, err := readLength(p.reader)
 		if err != nil {
-			return fmt.Errorf("failed to read module length: %w", err)
+			return fmt.Errorf("failed to read hash length: %w", err)
 		}
 
-		switch length {
-		case redisBloomBloomFilter:
-			if err := readBloomFilter(p.reader); err != nil {
+		for i := 0; i < length; i++ {
+			// ttl
+			if _, err := readLength(p.reader); err != nil {
 				return err
 			}
 
-			return nil
-		case redisBloomCuckooFilter:
-			if err := readCuckooFilter(p.reader); err != nil {
+			if err := p.skipStrings(2); err != nil {
 				return err
 			}
-
-			return nil
-		case redisBloomTopK, redisBloomTDigest, redisBloomCountMinSketch:
-			// TODO
-			return UnsupportedDataTypeError{DataType: typeModule2}
-		default: // other data types beside redisbloom
-			return UnsupportedDataTypeError{DataType: typeModule2}
 		}
 
-	case typeStreamListPacks:
-		// TODO
-	}
-
-	return nil
-}
-
-func (p *Parser) skipStrings(n int) error {
-	for i := 0; i < n; i++ {
-		if err := skipString(p.reader); err != nil {
+	case typeHashListPackExPreGA, typeHashListPackEx:
+		if _, err := p.readHashMinExpiry(); err != nil {
 			return err
 		}
-	}
 
-	return nil
-}
+		return p.skipStrings(1)
 
-// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/src/rebloom.c#L1116-L1131
-func readBloomFilter(r byteReader) error {
-	// size
-	_, err := redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
-	}
+	case typeListQuickList2:
+		length, err := readLength(p.reader)
+		if err != nil {
+			return fmt.Errorf("failed to read quicklist length: %w", err)
+		}
 
-	// numFilters
-	numFilters, err := redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
-	}
+		for i := 0; i < length; i++ {
+			// container
+			if _, err := readLength(p.reader); err != nil {
+				return err
+			}
 
-	// options
-	_, err = redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
-	}
+			if err := skipString(p.reader); err != nil {
+				return err
+			}
+		}
 
-	// growth
-	_, err = redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
-	}
+	case typeModule:
+		offset := p.source.Position()
 
-	for i := 0; i < numFilters; i++ {
-		// entries
-		_, err = redisModuleReadUnsigned(r)
+		id, err := readModuleID(p.reader)
 		if err != nil {
 			return err
 		}
 
-		// error
-		_, err = redisModuleReadDouble(r)
-		if err != nil {
-			return err
-		}
+		// Values without opcodes can only be skipped by decoding them
+		_, err = p.readLegacyModule(DataKey{Key: p.key, Database: p.db}, id, offset)
 
-		// hashes
-		_, err = redisModuleReadUnsigned(r)
-		if err != nil {
-			return err
-		}
-		// bpe
-		_, err = redisModuleReadDouble(r)
-		if err != nil {
-			return err
-		}
+		return err
 
-		// bits
-		_, err = redisModuleReadUnsigned(r)
-		if err != nil {
-			return err
-		}
-		// n2
-		_, err = redisModuleReadUnsigned(r)
-		if err != nil {
+	case typeModule2:
+		if _, err := readModuleID(p.reader); err != nil {
 			return err
 		}
 
-		// string buffer
-		_, err = redisModuleReadStringBuffer(r)
-		if err != nil {
-			return err
-		}
+		return skipModuleValues(p.reader)
 
-		// size
-		_, err = redisModuleReadUnsigned(r)
-		if err != nil {
-			return err
-		}
-	}
+	case typeStreamListPacks, typeStreamListPacks2, typeStreamListPacks3:
+		return skipStream(p.reader, *p.dataType)
 
-	if err := checkRdbModuleOpCode(r, rdbModuleOpcodeEOF); err != nil {
-		return err
+	default:
+		return UnsupportedDataTypeError{DataType: *p.dataType}
 	}
 
 	return nil
 }
 
-func readCuckooFilter(r byteReader) error {
-	numFilters, err := redisModuleReadUnsigned(r)
+func (p *Parser) readRawData(key DataKey) (*RawData, error) {
+	reader := p.reader
+	recorder := &recordingReader{reader: reader, buf: []byte{*p.dataType}}
+	p.reader = recorder
+
+	err := p.skipData()
+	p.reader = reader
+	p.dataType = nil
+
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read raw value: %w", err)
 	}
 
-	// numBuckets
-	_, err = redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
+	return &RawData{DataKey: key, Value: recorder.buf}, nil
+}
+
+func (p *Parser) readModule(key DataKey, id uint64) (interface{}, error) {
+	name, encodingVersion := DecodeModuleID(id)
+
+	decoder, ok := p.modules[name]
+	if !ok {
+		p.dataType = nil
+
+		return readModuleData(p.reader, key, id)
 	}
 
-	// numItems
-	_, err = redisModuleReadUnsigned(r)
+	data, err := decoder.DecodeModule(&ModuleReader{reader: p.reader}, key, encodingVersion)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to decode module %s: %w", name, err)
 	}
 
-	// numDeletes
-	_, err = redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
+	if iter, ok := data.(moduleIterator); ok {
+		p.iterator = &moduleEOFIterator{
+			Reader:     p.reader,
+			ModuleName: name,
+			Iterator:   iter,
+		}
+
+		return nil, errContinueLoop
 	}
-	// bucketSize
-	_, err = redisModuleReadUnsigned(r)
-	if err != nil {
-		return err
+
+	p.dataType = nil
+
+	if err := checkRdbModuleOpCode(p.reader, rdbModuleOpcodeEOF); err != nil {
+		return nil, fmt.Errorf("failed to read module %s EOF: %w", name, err)
 	}
-	// maxIterations
-	_, err = redisModuleReadUnsigned(r)
+
+	return data, nil
+}
+
+// readLegacyModule reads a value of the RDB_TYPE_MODULE type. Unlike
+// RDB_TYPE_MODULE_2, values are saved without opcodes and the EOF opcode, so
+// they can't be read without a decoder. offset is the position of the module
+// ID in the file.
+func (p *Parser) readLegacyModule(key DataKey, id uint64, offset int64) (interface{}, error) {
+	name, encodingVersion := DecodeModuleID(id)
+
+	decoder, ok := p.modules[name]
+	if !ok {
+		return nil, UnsupportedLegacyModuleError{
+			ModuleName:      name,
+			EncodingVersion: encodingVersion,
+			Offset:          offset,
+		}
+	}
+
+	data, err := decoder.DecodeModule(&ModuleReader{reader: p.reader, legacy: true}, key, encodingVersion)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to decode legacy module %s at offset %d: %w", name, offset, err)
 	}
-	// expansion
-	_, err = redisModuleReadUnsigned(r)
+
+	return data, nil
+}
+
+// readHashMinExpiry reads the minimum expiry of hash fields, which is only
+// stored in GA versions of hash field expiration types.
+func (p *Parser) readHashMinExpiry() (*time.Time, error) {
+	if *p.dataType != typeHashMetadata && *p.dataType != typeHashListPackEx {
+		return nil, nil
+	}
+
+	minExpiry, err := readMillisecondsTime(p.reader)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read hash min expiry: %w", err)
 	}
 
-	for i := 0; i < numFilters; i++ {
-		// filters[i].numBuckets
-		_, err = redisModuleReadUnsigned(r)
-		if err != nil {
-			return err
+	return minExpiry, nil
+}
+
+func (p *Parser) filterHashFields(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case *HashEntry:
+		if !p.HashFieldFilter(&v.DataKey, &v.HashValue) {
+			return nil, errContinueLoop
 		}
 
-		// string buffer
-		_, err = redisModuleReadStringBuffer(r)
-		if err != nil {
-			return err
+	case *HashData:
+		v.MinExpiry = nil
+
+		for field, fieldValue := range v.Value {
+			hashValue := HashValue{Index: field, Value: fieldValue}
+
+			if expiry, ok := v.FieldExpiry[field]; ok {
+				hashValue.Expiry = timePtr(expiry)
+			}
+
+			if !p.HashFieldFilter(&v.DataKey, &hashValue) {
+				delete(v.Value, field)
+				delete(v.FieldExpiry, field)
+
+				continue
+			}
+
+			if hashValue.Expiry != nil && (v.MinExpiry == nil || hashValue.Expiry.Before(*v.MinExpiry)) {
+				v.MinExpiry = hashValue.Expiry
+			}
+		}
+
+		if len(v.FieldExpiry) == 0 {
+			v.FieldExpiry = nil
 		}
 	}
 
-	if err := checkRdbModuleOpCode(r, rdbModuleOpcodeEOF); err != nil {
-		return err
+	return value, nil
+}
+
+func (p *Parser) skipStrings(n int) error {
+	for i := 0; i < n; i++ {
+		if err := skipString(p.reader); err != nil {
+			return err
+		}
 	}
 
 	return nil
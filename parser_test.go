@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 	"github.com/onsi/gomega/types"
@@ -72,6 +75,8 @@ var _ = Describe("Parser", func() {
 	testDumpFile("empty_database")
 	testDumpFile("multiple_databases")
 	testDumpFile("keys_with_expiry")
+	testDumpFile("redis_62_with_lru_idle")
+	testDumpFile("redis_62_with_lfu_freq")
 	testDumpFile("easily_compressible_string_key")
 	testDumpFile("integer_keys")
 	testDumpFile("non_ascii_values")
@@ -103,9 +108,44 @@ var _ = Describe("Parser", func() {
 	testDumpFile("zipmap_that_doesnt_compress")
 	testDumpFile("zipmap_with_big_values")
 
+	// Stream
+	testDumpFile("redis_50_with_streams")
+
+	// Listpack
+	testDumpFile("redis_70_with_listpacks")
+	testDumpFile("redis_72_with_listpacks")
+
+	// Hash field expiration
+	testDumpFile("redis_74_with_hash_field_expiry")
+
+	// Cluster
+	testDumpFile("redis_74_with_slot_info")
+
+	// KeyDB
+	testDumpFile("keydb_with_member_expiry")
+
+	// Functions
+	testDumpFile("redis_70_with_functions")
+	testDumpFile("redis_70_with_functions_pre_ga")
+
+	// Module
+	testDumpFile("redis_40_with_module")
+	testDumpFile("redis_40_with_legacy_module")
+	testDumpFile("redis_60_with_module_aux")
+	testDumpFile("redis_60_with_lua_scripts")
+
+	// RedisJSON
+	testDumpFile("redis_json")
+
+	// RedisTimeSeries
+	testDumpFile("redis_time_series")
+
 	// RedisBloom
 	testDumpFile("bloom_filter")
 	testDumpFile("cuckoo_filter")
+	testDumpFile("top_k")
+	testDumpFile("t_digest")
+	testDumpFile("count_min_sketch")
 
 	When("file is not started with the magic string", func() {
 		It("should return ErrInvalidMagicString", func() {
@@ -131,11 +171,11 @@ var _ = Describe("Parser", func() {
 		})
 	})
 
-	When("version > 9", func() {
+	When("version > 12", func() {
 		It("should return UnsupportedVersionError", func() {
-			parser := NewParser(bytes.NewBufferString("REDIS0010"))
+			parser := NewParser(bytes.NewBufferString("REDIS0013"))
 			_, err := parser.Next()
-			Expect(err).To(Equal(UnsupportedVersionError{Version: 10}))
+			Expect(err).To(Equal(UnsupportedVersionError{Version: 13}))
 		})
 	})
 
@@ -170,7 +210,7 @@ var _ = Describe("Parser", func() {
 						Expect(err).NotTo(HaveOccurred())
 
 						switch data.(type) {
-						case *Aux, *DatabaseSize:
+						case *Header, *Aux, *ModuleAux, *FunctionLibrary, *DatabaseSize, *SlotInfo:
 						default:
 							expectKeyTo(data, Not(Equal(key)))
 						}
@@ -205,9 +245,47 @@ var _ = Describe("Parser", func() {
 		testExcludeKey("regular_sorted_set", "force_sorted_set")
 		testExcludeKey("sorted_set_as_ziplist", "sorted_set_as_ziplist")
 
+		// Stream
+		testExcludeKey("redis_50_with_streams", "mystream")
+
+		// Listpack
+		testExcludeKey("redis_70_with_listpacks", "hash")
+		testExcludeKey("redis_70_with_listpacks", "zset")
+		testExcludeKey("redis_70_with_listpacks", "list")
+		testExcludeKey("redis_70_with_listpacks", "stream")
+		testExcludeKey("redis_72_with_listpacks", "set")
+		testExcludeKey("redis_72_with_listpacks", "stream")
+
+		// Hash field expiration
+		testExcludeKey("redis_74_with_hash_field_expiry", "hash_metadata")
+		testExcludeKey("redis_74_with_hash_field_expiry", "hash_listpack_ex")
+		testExcludeKey("redis_74_with_hash_field_expiry", "hash_metadata_pre_ga")
+		testExcludeKey("redis_74_with_hash_field_expiry", "hash_listpack_ex_pre_ga")
+
+		// Cluster
+		testExcludeKey("redis_74_with_slot_info", "foo")
+
+		// Functions
+		testExcludeKey("redis_70_with_functions", "foo")
+
+		// Module
+		testExcludeKey("redis_40_with_module", "foo")
+		testExcludeKey("redis_40_with_legacy_module", "json")
+
+		// RedisJSON
+		testExcludeKey("redis_json", "json")
+		testExcludeKey("redis_json", "json_indexed")
+
+		// RedisTimeSeries
+		testExcludeKey("redis_time_series", "ts")
+		testExcludeKey("redis_time_series", "ts_raw")
+
 		// RedisBloom
 		testExcludeKey("bloom_parser_filters", "newFilter2")
 		testExcludeKey("bloom_parser_filters", "newCuckooFilter2")
+		testExcludeKey("top_k", "topk")
+		testExcludeKey("t_digest", "tdigest")
+		testExcludeKey("count_min_sketch", "cms")
 
 		Describe("Filter by database", func() {
 			var file *os.File
@@ -228,6 +306,11 @@ var _ = Describe("Parser", func() {
 					}
 
 					Expect(err).NotTo(HaveOccurred())
+
+					if _, ok := data.(*Header); ok {
+						continue
+					}
+
 					expectKeyTo(data, Equal("key_in_second_database"))
 				}
 			})
@@ -269,5 +352,523 @@ var _ = Describe("Parser", func() {
 				}))
 			})
 		})
+
+		Describe("when key with idle is filtered", func() {
+			var file *os.File
+
+			setupFixture(&file, "redis_62_with_lru_idle")
+
+			It("should reset idle", func() {
+				parser := NewParser(file)
+				parser.KeyFilter = func(key *DataKey) bool {
+					return key.Key != "warm"
+				}
+
+				result := map[string]*time.Duration{}
+
+				for {
+					data, err := parser.Next()
+
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					Expect(err).NotTo(HaveOccurred())
+
+					if d, ok := data.(*StringData); ok {
+						result[d.Key] = d.Idle
+					}
+				}
+
+				Expect(result).To(Equal(map[string]*time.Duration{
+					"cold":  durationPtr(time.Hour),
+					"plain": nil,
+				}))
+			})
+		})
+	})
+
+	Describe("HashFieldFilter", func() {
+		var file *os.File
+
+		setupFixture(&file, "redis_74_with_hash_field_expiry")
+
+		It("should exclude expired fields", func() {
+			parser := NewParser(file)
+			parser.HashFieldFilter = func(key *DataKey, field *HashValue) bool {
+				return !field.Expired()
+			}
+
+			entries := map[string][]string{}
+			data := map[string]*HashData{}
+
+			for {
+				value, err := parser.Next()
+
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+
+				switch v := value.(type) {
+				case *HashEntry:
+					entries[v.Key] = append(entries[v.Key], v.Index)
+				case *HashData:
+					data[v.Key] = v
+				}
+			}
+
+			Expect(entries).To(HaveKeyWithValue("hash_listpack_ex", []string{"f2", "f3"}))
+			Expect(data).To(HaveKeyWithValue("hash_listpack_ex", PointTo(MatchFields(IgnoreExtras, Fields{
+				"Value":       Equal(map[string]string{"f2": "v2", "f3": "v3"}),
+				"FieldExpiry": HaveLen(1),
+				"MinExpiry":   PointTo(Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))),
+			}))))
+			Expect(entries).To(HaveKeyWithValue("hash_metadata", []string{"f1", "f2", "f3"}))
+		})
+
+		It("should set FieldExpiry and MinExpiry to nil when all fields with a TTL are excluded", func() {
+			parser := NewParser(file)
+			parser.HashFieldFilter = func(key *DataKey, field *HashValue) bool {
+				return field.Expiry == nil
+			}
+
+			data := map[string]*HashData{}
+
+			for {
+				value, err := parser.Next()
+
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+
+				if v, ok := value.(*HashData); ok {
+					data[v.Key] = v
+				}
+			}
+
+			Expect(data).To(HaveKeyWithValue("hash_listpack_ex", PointTo(MatchFields(IgnoreExtras, Fields{
+				"Value":       Equal(map[string]string{"f2": "v2"}),
+				"FieldExpiry": BeNil(),
+				"MinExpiry":   BeNil(),
+			}))))
+		})
+	})
+
+	Describe("Checksum", func() {
+		var data []byte
+
+		readAll := func(parser *Parser) error {
+			for {
+				_, err := parser.Next()
+
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			data, err = ioutil.ReadFile("fixtures/rdb_version_5_with_checksum.rdb")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should succeed when checksum is valid", func() {
+			Expect(readAll(NewParser(bytes.NewReader(data)))).To(Succeed())
+		})
+
+		When("data is corrupted", func() {
+			BeforeEach(func() {
+				// Replace the value "bar" with "baz"
+				i := bytes.Index(data, []byte("bar"))
+				Expect(i).To(BeNumerically(">=", 0))
+				data[i+2] = 'z'
+			})
+
+			It("should return ChecksumMismatchError", func() {
+				err := readAll(NewParser(bytes.NewReader(data)))
+				Expect(errors.As(err, &ChecksumMismatchError{})).To(BeTrue())
+				Expect(err).To(MatchError(ChecksumMismatchError{
+					Expected: 0x792e9530c6807218,
+					Actual:   0xeb64937397d1e4d8,
+				}))
+			})
+
+			It("should succeed when SkipChecksum is true", func() {
+				parser := NewParser(bytes.NewReader(data))
+				parser.SkipChecksum = true
+				Expect(readAll(parser)).To(Succeed())
+			})
+
+			It("should succeed when checksum is zero", func() {
+				copy(data[len(data)-8:], make([]byte, 8))
+				Expect(readAll(NewParser(bytes.NewReader(data)))).To(Succeed())
+			})
+		})
+	})
+	Describe("Version", func() {
+		var file *os.File
+
+		setupFixture(&file, "rdb_version_5_with_checksum")
+
+		It("should return the version after the header is read", func() {
+			parser := NewParser(file)
+			Expect(parser.Version()).To(Equal(0))
+			Expect(parser.Next()).To(Equal(&Header{Version: 5}))
+			Expect(parser.Version()).To(Equal(5))
+		})
+	})
+
+	DescribeTable("opcodes not supported in the version", func(version string, opCode byte) {
+		data := append([]byte("REDIS"+version), opCode)
+		parser := NewParser(bytes.NewReader(data))
+		Expect(parser.Next()).To(BeAssignableToTypeOf(&Header{}))
+		_, err := parser.Next()
+		Expect(err).To(MatchError(UnsupportedOpCodeError{OpCode: opCode, Version: parser.Version()}))
+	},
+		Entry("idle", "0008", byte(opCodeIdle)),
+		Entry("freq", "0008", byte(opCodeFreq)),
+		Entry("module aux", "0008", byte(opCodeModuleAux)),
+		Entry("function", "0009", byte(opCodeFunction2)),
+		Entry("function pre-GA", "0009", byte(opCodeFunctionPreGA)),
+		Entry("slot info", "0011", byte(opCodeSlotInfo)),
+	)
+
+	DescribeTable("data types not supported in the version", func(version string, dataType byte) {
+		data := append([]byte("REDIS"+version), dataType)
+		parser := NewParser(bytes.NewReader(data))
+		Expect(parser.Next()).To(BeAssignableToTypeOf(&Header{}))
+		_, err := parser.Next()
+		Expect(err).To(MatchError(UnsupportedDataTypeError{DataType: dataType, Version: parser.Version()}))
+	},
+		Entry("quicklist", "0006", byte(typeListQuickList)),
+		Entry("module", "0007", byte(typeModule2)),
+		Entry("stream", "0008", byte(typeStreamListPacks)),
+		Entry("set listpack", "0010", byte(typeSetListPack)),
+		Entry("hash listpack ex", "0011", byte(typeHashListPackEx)),
+	)
+
+	DescribeTable("Encoding", func(name string, expected Encoding) {
+		file, err := os.Open(fmt.Sprintf("fixtures/%s.rdb", name))
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		parser := NewParser(file)
+
+		for {
+			data, err := parser.Next()
+			Expect(err).NotTo(HaveOccurred())
+
+			switch data.(type) {
+			case *Header, *Aux, *DatabaseSize:
+				continue
+			}
+
+			break
+		}
+
+		Expect(parser.Encoding()).To(Equal(expected))
+	},
+		Entry("string", "keys_with_expiry", EncodingPlain),
+		Entry("linkedlist", "linkedlist", EncodingPlain),
+		Entry("zipmap", "zipmap_that_doesnt_compress", EncodingZipMap),
+		Entry("ziplist", "ziplist_with_integers", EncodingZipList),
+		Entry("intset", "intset_16", EncodingIntSet),
+		Entry("quicklist", "quicklist", EncodingQuickList),
+		Entry("listpack", "redis_72_with_listpacks", EncodingListPack),
+	)
+
+	Describe("Metadata", func() {
+		testMetadata := func(name string) {
+			Describe(name, func() {
+				var file *os.File
+
+				setupFixture(&file, name)
+
+				It("should match the golden file", func() {
+					parser := NewParser(file)
+
+					for {
+						_, err := parser.Next()
+
+						if errors.Is(err, io.EOF) {
+							break
+						}
+
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					Expect(parser.Metadata()).To(matchGoldenFile())
+				})
+			})
+		}
+
+		testMetadata("empty_database")
+		testMetadata("redis_40_with_module")
+		testMetadata("redis_60_with_lua_scripts")
+		testMetadata("redis_72_with_listpacks")
+
+		It("should ignore invalid aux values", func() {
+			data := []byte("REDIS0009\xfa\x0aredis-bits\x03abc\xfa\x05ctime\x01x\xfa\x08used-mem\x021k\xff")
+			data = append(data, make([]byte, 8)...)
+			parser := NewParser(bytes.NewReader(data))
+			Expect(parser.Next()).To(Equal(&Header{Version: 9}))
+			Expect(parser.Next()).To(Equal(&Aux{Key: "redis-bits", Value: "abc"}))
+			Expect(parser.Next()).To(Equal(&Aux{Key: "ctime", Value: "x"}))
+			Expect(parser.Next()).To(Equal(&Aux{Key: "used-mem", Value: "1k"}))
+			Expect(parser.Metadata()).To(Equal(Metadata{}))
+		})
+	})
+
+	Describe("KeyDB", func() {
+		var file *os.File
+
+		setupFixture(&file, "keydb_with_member_expiry")
+
+		readAll := func(parser *Parser) []interface{} {
+			var result []interface{}
+
+			for {
+				data, err := parser.Next()
+
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+				result = append(result, data)
+			}
+
+			return result
+		}
+
+		It("should attach expiries of members", func() {
+			parser := NewParser(file)
+			parser.KeyDB = true
+			Expect(readAll(parser)).To(matchGoldenFile())
+		})
+
+		It("should drop expiries of members of filtered keys", func() {
+			parser := NewParser(file)
+			parser.KeyDB = true
+			parser.KeyFilter = func(key *DataKey) bool {
+				return key.Key != "myzset"
+			}
+
+			var keys []string
+
+			for _, data := range readAll(parser) {
+				switch v := data.(type) {
+				case *Aux:
+					Expect(v.Key).NotTo(HavePrefix("keydb-subexpire-"))
+				case *StringData:
+					keys = append(keys, v.Key)
+				case *SetData:
+					keys = append(keys, v.Key)
+				case *HashData:
+					keys = append(keys, v.Key)
+				}
+			}
+
+			Expect(keys).To(Equal([]string{"myset", "myhash", "plain"}))
+		})
+	})
+
+	Describe("Slot", func() {
+		var file *os.File
+
+		setupFixture(&file, "redis_74_with_slot_info")
+
+		It("should track the slot of keys", func() {
+			parser := NewParser(file)
+			Expect(parser.Slot()).To(Equal(-1))
+
+			result := map[string]int{}
+
+			for {
+				data, err := parser.Next()
+
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+
+				if d, ok := data.(*StringData); ok {
+					result[d.Key] = parser.Slot()
+				}
+			}
+
+			Expect(result).To(Equal(map[string]int{
+				"bar":      5061,
+				"foo":      12182,
+				"bar{foo}": 12182,
+			}))
+		})
+	})
+
+	Describe("RegisterModule", func() {
+		var file *os.File
+
+		setupFixture(&file, "redis_40_with_module")
+
+		findModuleData := func(parser *Parser) (*testModuleData, error) {
+			for {
+				data, err := parser.Next()
+				if err != nil {
+					return nil, err
+				}
+
+				if v, ok := data.(*testModuleData); ok {
+					return v, nil
+				}
+			}
+		}
+
+		It("should decode values with the registered decoder", func() {
+			parser := NewParser(file)
+			parser.RegisterModule("ReJSON-RL", testModuleDecoder{})
+
+			data, err := findModuleData(parser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(&testModuleData{
+				DataKey:         DataKey{Key: "foo"},
+				EncodingVersion: 0,
+				Values:          []interface{}{uint64(32), uint64(2), uint64(128), "name", uint64(2), "bb", uint64(128), "counts", uint64(8), uint64(4)},
+			}))
+		})
+
+		It("should return the error of the decoder", func() {
+			decoderErr := errors.New("decoder error")
+			parser := NewParser(file)
+			parser.RegisterModule("ReJSON-RL", testModuleDecoder{err: decoderErr})
+
+			_, err := findModuleData(parser)
+			Expect(err).To(MatchError(decoderErr))
+		})
+	})
+
+	Describe("Legacy module", func() {
+		var file *os.File
+
+		setupFixture(&file, "redis_40_with_unknown_legacy_module")
+
+		It("should return UnsupportedLegacyModuleError when decoder is not registered", func() {
+			parser := NewParser(file)
+
+			for {
+				_, err := parser.Next()
+				if err != nil {
+					Expect(err).To(MatchError(UnsupportedLegacyModuleError{
+						ModuleName:      "test__rdb",
+						EncodingVersion: 1,
+						Offset:          65,
+					}))
+
+					break
+				}
+			}
+		})
+
+		It("should decode values without opcodes with the registered decoder", func() {
+			parser := NewParser(file)
+			parser.RegisterModule("test__rdb", testLegacyModuleDecoder{})
+
+			var result []interface{}
+
+			for {
+				data, err := parser.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+				result = append(result, data)
+			}
+
+			Expect(result).To(ContainElement(&testModuleData{
+				DataKey:         DataKey{Key: "unknown"},
+				EncodingVersion: 1,
+				Values:          []interface{}{uint64(1), int64(2)},
+			}))
+		})
 	})
 })
+
+type testModuleData struct {
+	DataKey
+	EncodingVersion int
+	Values          []interface{}
+}
+
+type testModuleDecoder struct {
+	err error
+}
+
+func (t testModuleDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	data := &testModuleData{DataKey: key, EncodingVersion: encodingVersion}
+
+	for _, typ := range []ModuleValueType{
+		ModuleValueUInt, ModuleValueUInt, ModuleValueUInt, ModuleValueString, ModuleValueUInt,
+		ModuleValueString, ModuleValueUInt, ModuleValueString, ModuleValueUInt, ModuleValueUInt,
+	} {
+		var (
+			value interface{}
+			err   error
+		)
+
+		if typ == ModuleValueUInt {
+			value, err = r.ReadUnsigned()
+		} else {
+			value, err = r.ReadString()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		data.Values = append(data.Values, value)
+	}
+
+	return data, nil
+}
+
+type testLegacyModuleDecoder struct{}
+
+func (testLegacyModuleDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if !r.Legacy() {
+		return nil, errors.New("module is not legacy")
+	}
+
+	if _, err := r.ReadValue(); !errors.Is(err, ErrUntypedModuleValue) {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+
+	unsigned, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := r.ReadSigned()
+	if err != nil {
+		return nil, err
+	}
+
+	return &testModuleData{
+		DataKey:         key,
+		EncodingVersion: encodingVersion,
+		Values:          []interface{}{unsigned, signed},
+	}, nil
+}
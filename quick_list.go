@@ -5,11 +5,17 @@ import (
 	"io"
 )
 
+const (
+	quickListNodeContainerPlain  = 1
+	quickListNodeContainerPacked = 2
+)
+
 type quickListIterator struct {
 	DataKey     DataKey
 	Reader      byteReader
 	ValueReader valueReader
 	Mapper      collectionMapper
+	ListPack    bool
 
 	index       int
 	length      int
@@ -59,6 +65,10 @@ func (q *quickListIterator) Next() (interface{}, error) {
 	}
 
 	if q.iterator == nil {
+		if q.ListPack {
+			return q.nextListPackNode()
+		}
+
 		q.iterator = &zipListIterator{
 			DataKey:     q.DataKey,
 			Reader:      q.Reader,
@@ -71,6 +81,47 @@ func (q *quickListIterator) Next() (interface{}, error) {
 	return q.iterator.Next()
 }
 
+func (q *quickListIterator) nextListPackNode() (interface{}, error) {
+	container, err := readLength(q.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quicklist node container: %w", err)
+	}
+
+	switch container {
+	case quickListNodeContainerPlain:
+		value, err := readString(q.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read quicklist plain node: %w", err)
+		}
+
+		entry, err := q.MapEntry(&collectionEntry{
+			DataKey: q.DataKey,
+			Length:  1,
+			Value:   value,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		q.index++
+
+		return entry, nil
+
+	case quickListNodeContainerPacked:
+		q.iterator = &listPackIterator{
+			DataKey:     q.DataKey,
+			Reader:      q.Reader,
+			ValueReader: q.ValueReader,
+			Mapper:      q,
+			ValueLength: 1,
+		}
+
+		return q.iterator.Next()
+	}
+
+	return nil, QuickListContainerError{Container: container}
+}
+
 func (q *quickListIterator) MapHead(head *collectionHead) (interface{}, error) {
 	return nil, errContinueLoop
 }
@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/tommy351/rdb-go/internal/convert"
 )
@@ -13,18 +14,23 @@ type SetHead struct {
 	Length int
 }
 
-// SetEntry is returned when a new set entry is read.
+// SetEntry is returned when a new set entry is read. MemberExpiry is only
+// available when the member has an expiry set by EXPIREMEMBER in KeyDB.
 type SetEntry struct {
 	DataKey
-	Index  int
-	Length int
-	Value  string
+	Index        int
+	Length       int
+	Value        string
+	MemberExpiry *time.Time
 }
 
-// SetData is returned when all entries in a set are all read.
+// SetData is returned when all entries in a set are all read. MemberExpiry
+// contains the expiry of members set by EXPIREMEMBER in KeyDB, and it is nil
+// when no members have an expiry.
 type SetData struct {
 	DataKey
-	Value []string
+	Value        []string
+	MemberExpiry map[string]time.Time
 }
 
 type setMapper struct{}
@@ -67,3 +73,14 @@ func (setMapper) MapSlice(slice *collectionSlice) (interface{}, error) {
 
 	return data, nil
 }
+
+type setListPackValueReader struct{}
+
+func (setListPackValueReader) ReadValue(r byteReader) (interface{}, error) {
+	value, err := readListPackEntry(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read set value from listpack: %w", err)
+	}
+
+	return value, nil
+}
@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/tommy351/rdb-go/internal/convert"
 )
@@ -19,18 +20,24 @@ type SortedSetHead struct {
 	Length int
 }
 
-// SortedSetEntry is returned when a new sorted set entry is read.
+// SortedSetEntry is returned when a new sorted set entry is read. MemberExpiry
+// is only available when the member has an expiry set by EXPIREMEMBER in
+// KeyDB.
 type SortedSetEntry struct {
 	DataKey
 	SortedSetValue
-	Index  int
-	Length int
+	Index        int
+	Length       int
+	MemberExpiry *time.Time
 }
 
 // SortedSetData is returned when all entries in a sorted set are all read.
+// MemberExpiry contains the expiry of members set by EXPIREMEMBER in KeyDB, and
+// it is nil when no members have an expiry.
 type SortedSetData struct {
 	DataKey
-	Value []SortedSetValue
+	Value        []SortedSetValue
+	MemberExpiry map[string]time.Time
 }
 
 type sortedSetValueReader struct {
@@ -121,3 +128,27 @@ func (s sortedSetZipListValueReader) ReadValue(r byteReader) (interface{}, error
 		Score: scoreFloat,
 	}, nil
 }
+
+type sortedSetListPackValueReader struct{}
+
+func (sortedSetListPackValueReader) ReadValue(r byteReader) (interface{}, error) {
+	value, err := readListPackString(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zset value from listpack: %w", err)
+	}
+
+	score, err := readListPackEntry(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zset score from listpack: %w", err)
+	}
+
+	scoreFloat, err := convert.Float64(score)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert zset score to float64: %w", err)
+	}
+
+	return SortedSetValue{
+		Value: value,
+		Score: scoreFloat,
+	}, nil
+}
@@ -0,0 +1,608 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	streamItemFlagDeleted    = 1
+	streamItemFlagSameFields = 2
+
+	streamIDLength = 16
+)
+
+// StreamID is the ID of a stream entry.
+type StreamID struct {
+	Millis   uint64
+	Sequence uint64
+}
+
+// String returns the ID in the "<millis>-<sequence>" format used by Redis.
+func (s StreamID) String() string {
+	return strconv.FormatUint(s.Millis, 10) + "-" + strconv.FormatUint(s.Sequence, 10)
+}
+
+// StreamField contains a field-value pair of a stream entry.
+type StreamField struct {
+	Field string
+	Value string
+}
+
+// StreamValue contains the ID and the fields of a stream entry.
+type StreamValue struct {
+	ID     StreamID
+	Fields []StreamField
+}
+
+// StreamHead contains the key of a stream. It is returned when a stream is read
+// first time. The length of a stream is stored after its entries, so it is only
+// available in StreamData.
+type StreamHead struct {
+	DataKey
+}
+
+// StreamEntry is returned when a new stream entry is read. Deleted entries are
+// not returned.
+type StreamEntry struct {
+	DataKey
+	StreamValue
+	Index int
+}
+
+// StreamPendingEntry is a message which was delivered to a consumer but has not
+// been acknowledged yet.
+type StreamPendingEntry struct {
+	ID            StreamID
+	DeliveryTime  time.Time
+	DeliveryCount int
+}
+
+// StreamConsumer contains a consumer of a consumer group and its pending entries.
+// ActiveTime is only available since RDB version 11.
+type StreamConsumer struct {
+	Name       string
+	SeenTime   time.Time
+	ActiveTime *time.Time
+	Pending    []StreamPendingEntry
+}
+
+// StreamGroup contains a consumer group of a stream. Pending is the global
+// pending entries list of the group. EntriesRead is only available since RDB
+// version 10.
+type StreamGroup struct {
+	Name            string
+	LastDeliveredID StreamID
+	EntriesRead     int
+	Pending         []StreamPendingEntry
+	Consumers       []StreamConsumer
+}
+
+// StreamData is returned when all entries in a stream are all read. FirstID,
+// MaxDeletedID and EntriesAdded are only available since RDB version 10.
+type StreamData struct {
+	DataKey
+	Value        []StreamValue
+	Length       int
+	LastID       StreamID
+	FirstID      StreamID
+	MaxDeletedID StreamID
+	EntriesAdded int
+	Groups       []StreamGroup
+}
+
+type streamIterator struct {
+	DataKey  DataKey
+	Reader   byteReader
+	DataType byte
+
+	initialized  bool
+	done         bool
+	nodeIndex    int
+	nodeLength   int
+	buf          byteReader
+	masterID     StreamID
+	masterFields []string
+	remaining    int
+	index        int
+	values       []StreamValue
+}
+
+func (s *streamIterator) Next() (interface{}, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	if !s.initialized {
+		length, err := readLength(s.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stream listpacks length: %w", err)
+		}
+
+		s.initialized = true
+		s.nodeLength = length
+
+		return &StreamHead{DataKey: s.DataKey}, nil
+	}
+
+	for {
+		if s.buf == nil {
+			if s.nodeIndex == s.nodeLength {
+				return s.readData()
+			}
+
+			if err := s.readNode(); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.remaining == 0 {
+			if err := readListPackEnd(s.buf); err != nil {
+				return nil, err
+			}
+
+			s.buf = nil
+			s.nodeIndex++
+
+			continue
+		}
+
+		value, deleted, err := s.readEntry()
+		if err != nil {
+			return nil, err
+		}
+
+		s.remaining--
+
+		if deleted {
+			continue
+		}
+
+		entry := &StreamEntry{
+			DataKey:     s.DataKey,
+			StreamValue: value,
+			Index:       s.index,
+		}
+
+		s.index++
+		s.values = append(s.values, value)
+
+		return entry, nil
+	}
+}
+
+func (s *streamIterator) readNode() error {
+	key, err := readStringEncoding(s.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to read stream node key: %w", err)
+	}
+
+	if s.masterID, err = parseStreamID(key); err != nil {
+		return err
+	}
+
+	buf, err := readStringEncoding(s.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to read stream listpack: %w", err)
+	}
+
+	s.buf = newSliceReader(buf)
+
+	if _, err := readListPackHeader(s.buf); err != nil {
+		return err
+	}
+
+	count, err := readListPackInteger(s.buf)
+	if err != nil {
+		return fmt.Errorf("failed to read stream entry count: %w", err)
+	}
+
+	deleted, err := readListPackInteger(s.buf)
+	if err != nil {
+		return fmt.Errorf("failed to read stream deleted entry count: %w", err)
+	}
+
+	numFields, err := readListPackInteger(s.buf)
+	if err != nil {
+		return fmt.Errorf("failed to read stream master field count: %w", err)
+	}
+
+	s.masterFields = make([]string, numFields)
+
+	for i := range s.masterFields {
+		if s.masterFields[i], err = readListPackString(s.buf); err != nil {
+			return fmt.Errorf("failed to read stream master field: %w", err)
+		}
+	}
+
+	// The master entry is terminated by a zero
+	if _, err := readListPackEntry(s.buf); err != nil {
+		return fmt.Errorf("failed to read stream master entry terminator: %w", err)
+	}
+
+	s.remaining = int(count + deleted)
+
+	return nil
+}
+
+func (s *streamIterator) readEntry() (StreamValue, bool, error) {
+	var value StreamValue
+
+	flags, err := readListPackInteger(s.buf)
+	if err != nil {
+		return value, false, fmt.Errorf("failed to read stream entry flags: %w", err)
+	}
+
+	msDiff, err := readListPackInteger(s.buf)
+	if err != nil {
+		return value, false, fmt.Errorf("failed to read stream entry ms diff: %w", err)
+	}
+
+	seqDiff, err := readListPackInteger(s.buf)
+	if err != nil {
+		return value, false, fmt.Errorf("failed to read stream entry seq diff: %w", err)
+	}
+
+	value.ID = StreamID{
+		Millis:   s.masterID.Millis + uint64(msDiff),
+		Sequence: s.masterID.Sequence + uint64(seqDiff),
+	}
+
+	sameFields := flags&streamItemFlagSameFields != 0
+	numFields := int64(len(s.masterFields))
+
+	if !sameFields {
+		if numFields, err = readListPackInteger(s.buf); err != nil {
+			return value, false, fmt.Errorf("failed to read stream entry field count: %w", err)
+		}
+	}
+
+	value.Fields = make([]StreamField, numFields)
+
+	for i := range value.Fields {
+		if sameFields {
+			value.Fields[i].Field = s.masterFields[i]
+		} else if value.Fields[i].Field, err = readListPackString(s.buf); err != nil {
+			return value, false, fmt.Errorf("failed to read stream entry field: %w", err)
+		}
+
+		if value.Fields[i].Value, err = readListPackString(s.buf); err != nil {
+			return value, false, fmt.Errorf("failed to read stream entry value: %w", err)
+		}
+	}
+
+	// lp-count
+	if _, err := readListPackEntry(s.buf); err != nil {
+		return value, false, fmt.Errorf("failed to read stream entry lp-count: %w", err)
+	}
+
+	return value, flags&streamItemFlagDeleted != 0, nil
+}
+
+func (s *streamIterator) readData() (interface{}, error) {
+	data := &StreamData{
+		DataKey: s.DataKey,
+		Value:   s.values,
+	}
+
+	if err := readStreamMetadata(s.Reader, s.DataType, data); err != nil {
+		return nil, err
+	}
+
+	groups, err := readStreamGroups(s.Reader, s.DataType)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Groups = groups
+	s.done = true
+
+	return data, nil
+}
+
+func readStreamMetadata(r byteReader, dataType byte, data *StreamData) error {
+	var err error
+
+	if data.Length, err = readLength(r); err != nil {
+		return fmt.Errorf("failed to read stream length: %w", err)
+	}
+
+	if data.LastID, err = readStreamID(r); err != nil {
+		return fmt.Errorf("failed to read stream last ID: %w", err)
+	}
+
+	if dataType == typeStreamListPacks {
+		return nil
+	}
+
+	if data.FirstID, err = readStreamID(r); err != nil {
+		return fmt.Errorf("failed to read stream first ID: %w", err)
+	}
+
+	if data.MaxDeletedID, err = readStreamID(r); err != nil {
+		return fmt.Errorf("failed to read stream max deleted ID: %w", err)
+	}
+
+	if data.EntriesAdded, err = readLength(r); err != nil {
+		return fmt.Errorf("failed to read stream entries added: %w", err)
+	}
+
+	return nil
+}
+
+func readStreamGroups(r byteReader, dataType byte) ([]StreamGroup, error) {
+	length, err := readLength(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream group length: %w", err)
+	}
+
+	groups := make([]StreamGroup, length)
+
+	for i := range groups {
+		if groups[i], err = readStreamGroup(r, dataType); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+func readStreamGroup(r byteReader, dataType byte) (StreamGroup, error) {
+	var (
+		group StreamGroup
+		err   error
+	)
+
+	if group.Name, err = readString(r); err != nil {
+		return group, fmt.Errorf("failed to read stream group name: %w", err)
+	}
+
+	if group.LastDeliveredID, err = readStreamID(r); err != nil {
+		return group, fmt.Errorf("failed to read stream group last delivered ID: %w", err)
+	}
+
+	if dataType != typeStreamListPacks {
+		if group.EntriesRead, err = readLength(r); err != nil {
+			return group, fmt.Errorf("failed to read stream group entries read: %w", err)
+		}
+	}
+
+	pelLength, err := readLength(r)
+	if err != nil {
+		return group, fmt.Errorf("failed to read stream group PEL length: %w", err)
+	}
+
+	group.Pending = make([]StreamPendingEntry, pelLength)
+	pel := make(map[StreamID]StreamPendingEntry, pelLength)
+
+	for i := range group.Pending {
+		entry, err := readStreamPendingEntry(r)
+		if err != nil {
+			return group, err
+		}
+
+		group.Pending[i] = entry
+		pel[entry.ID] = entry
+	}
+
+	consumerLength, err := readLength(r)
+	if err != nil {
+		return group, fmt.Errorf("failed to read stream consumer length: %w", err)
+	}
+
+	group.Consumers = make([]StreamConsumer, consumerLength)
+
+	for i := range group.Consumers {
+		if group.Consumers[i], err = readStreamConsumer(r, dataType, pel); err != nil {
+			return group, err
+		}
+	}
+
+	return group, nil
+}
+
+func readStreamPendingEntry(r byteReader) (StreamPendingEntry, error) {
+	var entry StreamPendingEntry
+
+	buf, err := r.ReadBytes(streamIDLength)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read stream pending entry ID: %w", err)
+	}
+
+	if entry.ID, err = parseStreamID(buf); err != nil {
+		return entry, err
+	}
+
+	deliveryTime, err := readMillisecondsTime(r)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read stream pending entry delivery time: %w", err)
+	}
+
+	entry.DeliveryTime = *deliveryTime
+
+	if entry.DeliveryCount, err = readLength(r); err != nil {
+		return entry, fmt.Errorf("failed to read stream pending entry delivery count: %w", err)
+	}
+
+	return entry, nil
+}
+
+func readStreamConsumer(r byteReader, dataType byte, pel map[StreamID]StreamPendingEntry) (StreamConsumer, error) {
+	var (
+		consumer StreamConsumer
+		err      error
+	)
+
+	if consumer.Name, err = readString(r); err != nil {
+		return consumer, fmt.Errorf("failed to read stream consumer name: %w", err)
+	}
+
+	seenTime, err := readMillisecondsTime(r)
+	if err != nil {
+		return consumer, fmt.Errorf("failed to read stream consumer seen time: %w", err)
+	}
+
+	consumer.SeenTime = *seenTime
+
+	if dataType == typeStreamListPacks3 {
+		if consumer.ActiveTime, err = readMillisecondsTime(r); err != nil {
+			return consumer, fmt.Errorf("failed to read stream consumer active time: %w", err)
+		}
+	}
+
+	pelLength, err := readLength(r)
+	if err != nil {
+		return consumer, fmt.Errorf("failed to read stream consumer PEL length: %w", err)
+	}
+
+	consumer.Pending = make([]StreamPendingEntry, pelLength)
+
+	for i := range consumer.Pending {
+		buf, err := r.ReadBytes(streamIDLength)
+		if err != nil {
+			return consumer, fmt.Errorf("failed to read stream consumer pending entry ID: %w", err)
+		}
+
+		id, err := parseStreamID(buf)
+		if err != nil {
+			return consumer, err
+		}
+
+		// The consumer PEL only contains IDs, the delivery information is
+		// shared with the group PEL.
+		entry, ok := pel[id]
+		if !ok {
+			return consumer, StreamPendingEntryNotFoundError{Consumer: consumer.Name, ID: id}
+		}
+
+		consumer.Pending[i] = entry
+	}
+
+	return consumer, nil
+}
+
+func skipStreamGroups(r byteReader, dataType byte) error {
+	length, err := readLength(r)
+	if err != nil {
+		return fmt.Errorf("failed to read stream group length: %w", err)
+	}
+
+	for i := 0; i < length; i++ {
+		// name
+		if err := skipString(r); err != nil {
+			return err
+		}
+
+		// last ID
+		if _, err := readStreamID(r); err != nil {
+			return err
+		}
+
+		// entries read
+		if dataType != typeStreamListPacks {
+			if _, err := readLength(r); err != nil {
+				return err
+			}
+		}
+
+		pelLength, err := readLength(r)
+		if err != nil {
+			return err
+		}
+
+		for j := 0; j < pelLength; j++ {
+			// ID and delivery time
+			if err := skipBytes(r, streamIDLength+8); err != nil {
+				return err
+			}
+
+			// delivery count
+			if _, err := readLength(r); err != nil {
+				return err
+			}
+		}
+
+		consumerLength, err := readLength(r)
+		if err != nil {
+			return err
+		}
+
+		for j := 0; j < consumerLength; j++ {
+			// name
+			if err := skipString(r); err != nil {
+				return err
+			}
+
+			// seen time
+			if err := skipBytes(r, 8); err != nil {
+				return err
+			}
+
+			// active time
+			if dataType == typeStreamListPacks3 {
+				if err := skipBytes(r, 8); err != nil {
+					return err
+				}
+			}
+
+			pelLength, err := readLength(r)
+			if err != nil {
+				return err
+			}
+
+			if err := skipBytes(r, streamIDLength*pelLength); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func skipStream(r byteReader, dataType byte) error {
+	length, err := readLength(r)
+	if err != nil {
+		return fmt.Errorf("failed to read stream listpacks length: %w", err)
+	}
+
+	// Node keys and listpacks
+	for i := 0; i < length*2; i++ {
+		if err := skipString(r); err != nil {
+			return err
+		}
+	}
+
+	if err := readStreamMetadata(r, dataType, &StreamData{}); err != nil {
+		return err
+	}
+
+	return skipStreamGroups(r, dataType)
+}
+
+func readStreamID(r byteReader) (StreamID, error) {
+	ms, err := readLength(r)
+	if err != nil {
+		return StreamID{}, err
+	}
+
+	seq, err := readLength(r)
+	if err != nil {
+		return StreamID{}, err
+	}
+
+	return StreamID{Millis: uint64(ms), Sequence: uint64(seq)}, nil
+}
+
+func parseStreamID(buf []byte) (StreamID, error) {
+	if len(buf) != streamIDLength {
+		return StreamID{}, StreamIDLengthError{Length: len(buf)}
+	}
+
+	return StreamID{
+		Millis:   binary.BigEndian.Uint64(buf[:8]),
+		Sequence: binary.BigEndian.Uint64(buf[8:]),
+	}, nil
+}
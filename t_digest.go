@@ -0,0 +1,219 @@
+package rdb
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/tommy351/rdb-go/internal/convert"
+)
+
+const (
+	redisBloomTDigestModule          = "TDIS-TYPE"
+	redisBloomTDigestEncodingVersion = 0
+)
+
+// TDigest represents a t-digest sketch implemented by RedisBloom.
+type TDigest struct {
+	DataKey
+	Compression       float64
+	Capacity          int64
+	MergedNodes       int64
+	UnmergedNodes     int64
+	TotalCompressions int64
+	MergedWeight      float64
+	UnmergedWeight    float64
+	Min               float64
+	Max               float64
+	// Centroids contains merged centroids followed by unmerged centroids.
+	Centroids []TDigestCentroid
+}
+
+// TDigestCentroid is a centroid of a t-digest sketch.
+type TDigestCentroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// Quantile returns the estimated value at the quantile q, which must be
+// between 0 and 1. Unlike TDIGEST.QUANTILE, unmerged centroids are not
+// compressed before the estimation, so the result may be slightly different.
+// NaN is returned when the sketch is empty or q is out of range.
+//
+// https://github.com/RedisBloom/t-digest-c/blob/master/src/tdigest.c
+// nolint: gocognit, gocyclo
+func (t *TDigest) Quantile(q float64) float64 {
+	if q < 0 || q > 1 || len(t.Centroids) == 0 {
+		return math.NaN()
+	}
+
+	centroids := make([]TDigestCentroid, len(t.Centroids))
+	copy(centroids, t.Centroids)
+	sort.SliceStable(centroids, func(i, j int) bool {
+		return centroids[i].Mean < centroids[j].Mean
+	})
+
+	n := len(centroids)
+
+	if n == 1 {
+		return centroids[0].Mean
+	}
+
+	var totalWeight float64
+
+	for _, c := range centroids {
+		totalWeight += c.Weight
+	}
+
+	index := q * totalWeight
+
+	if index < 1 {
+		return t.Min
+	}
+
+	left := centroids[0]
+
+	// There is a single sample at min, so we interpolate with less weight
+	if left.Weight > 1 && index < left.Weight/2 {
+		return t.Min + (index-1)/(left.Weight/2-1)*(left.Mean-t.Min)
+	}
+
+	if index > totalWeight-1 {
+		return t.Max
+	}
+
+	right := centroids[n-1]
+
+	// There is a single sample at max, so we interpolate with less weight
+	if right.Weight > 1 && totalWeight-index <= right.Weight/2 {
+		return t.Max - (totalWeight-index-1)/(right.Weight/2-1)*(t.Max-right.Mean)
+	}
+
+	weightSoFar := left.Weight / 2
+
+	for i := 0; i < n-1; i++ {
+		dw := (centroids[i].Weight + centroids[i+1].Weight) / 2
+
+		if weightSoFar+dw > index {
+			var leftUnit, rightUnit float64
+
+			if centroids[i].Weight == 1 {
+				if index-weightSoFar < 0.5 {
+					return centroids[i].Mean
+				}
+
+				leftUnit = 0.5
+			}
+
+			if centroids[i+1].Weight == 1 {
+				if weightSoFar+dw-index <= 0.5 {
+					return centroids[i+1].Mean
+				}
+
+				rightUnit = 0.5
+			}
+
+			z1 := index - weightSoFar - leftUnit
+			z2 := weightSoFar + dw - index - rightUnit
+
+			return weightedAverage(centroids[i].Mean, z2, centroids[i+1].Mean, z1)
+		}
+
+		weightSoFar += dw
+	}
+
+	z1 := index - totalWeight - right.Weight/2
+	z2 := right.Weight/2 - z1
+
+	return weightedAverage(right.Mean, z1, t.Max, z2)
+}
+
+func weightedAverage(x1, w1, x2, w2 float64) float64 {
+	if x1 > x2 {
+		x1, w1, x2, w2 = x2, w2, x1, w1
+	}
+
+	x := (x1*w1 + x2*w2) / (w1 + w2)
+
+	return math.Max(x1, math.Min(x, x2))
+}
+
+type tDigestDecoder struct{}
+
+// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/src/rm_tdigest.c
+func (tDigestDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion > redisBloomTDigestEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisBloomTDigestModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	var err error
+
+	td := &TDigest{DataKey: key}
+
+	if td.Compression, err = r.ReadDouble(); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []*int64{&td.Capacity, &td.MergedNodes, &td.UnmergedNodes, &td.TotalCompressions} {
+		if *field, err = readModuleInt64(r); err != nil {
+			return nil, err
+		}
+	}
+
+	// Weights are saved as integers
+	if td.MergedWeight, err = readModuleFloat64(r); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []*float64{&td.UnmergedWeight, &td.Min, &td.Max} {
+		if *field, err = readModuleFloat64(r); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := int64(0); i < td.MergedNodes+td.UnmergedNodes; i++ {
+		var centroid TDigestCentroid
+
+		if centroid.Mean, err = r.ReadDouble(); err != nil {
+			return nil, err
+		}
+
+		if centroid.Weight, err = readModuleFloat64(r); err != nil {
+			return nil, err
+		}
+
+		td.Centroids = append(td.Centroids, centroid)
+	}
+
+	return td, nil
+}
+
+func readModuleInt64(r *ModuleReader) (int64, error) {
+	value, err := r.ReadValue()
+	if err != nil {
+		return 0, err
+	}
+
+	if value.Type == ModuleValueString {
+		return 0, fmt.Errorf("unexpected module string: %w", convert.Error{Value: value.Value, Type: "int64"})
+	}
+
+	return convert.Int64(value.Value)
+}
+
+func readModuleFloat64(r *ModuleReader) (float64, error) {
+	value, err := r.ReadValue()
+	if err != nil {
+		return 0, err
+	}
+
+	if value.Type == ModuleValueString {
+		return 0, fmt.Errorf("unexpected module string: %w", convert.Error{Value: value.Value, Type: "float64"})
+	}
+
+	return convert.Float64(value.Value)
+}
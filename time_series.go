@@ -0,0 +1,693 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	redisTimeSeriesModule = "TSDB-TYPE"
+
+	// https://github.com/RedisTimeSeries/RedisTimeSeries/blob/master/src/rdb.h
+	timeSeriesUncompressedVersion    = 1
+	timeSeriesChunkSizeVersion       = 2
+	timeSeriesDuplicatePolicyVersion = 3
+	timeSeriesOverflowVersion        = 4
+	timeSeriesAlignmentVersion       = 6
+	timeSeriesEncodingVersion        = timeSeriesAlignmentVersion
+
+	timeSeriesOptionUncompressed = 1
+	timeSeriesSampleSize         = 16
+
+	// Bit sizes of the Gorilla compression.
+	timeSeriesDoubleLeading     = 5
+	timeSeriesDoubleBlockSize   = 6
+	timeSeriesDoubleBlockAdjust = 1
+)
+
+// Lengths of delta of deltas of timestamps in Gorilla chunks, indexed by the
+// number of leading 1 bits of the control bits.
+// nolint: gochecknoglobals
+var timeSeriesDeltaLengths = []uint{0, 5, 8, 11, 14, 32, 64}
+
+// TimeSeriesDuplicatePolicy is the policy for handling samples with identical
+// timestamps.
+type TimeSeriesDuplicatePolicy uint64
+
+// Duplicate policies of time series.
+const (
+	TimeSeriesDuplicatePolicyNone TimeSeriesDuplicatePolicy = iota
+	TimeSeriesDuplicatePolicyBlock
+	TimeSeriesDuplicatePolicyLast
+	TimeSeriesDuplicatePolicyFirst
+	TimeSeriesDuplicatePolicyMin
+	TimeSeriesDuplicatePolicyMax
+	TimeSeriesDuplicatePolicySum
+)
+
+func (t TimeSeriesDuplicatePolicy) String() string {
+	switch t {
+	case TimeSeriesDuplicatePolicyBlock:
+		return "block"
+	case TimeSeriesDuplicatePolicyLast:
+		return "last"
+	case TimeSeriesDuplicatePolicyFirst:
+		return "first"
+	case TimeSeriesDuplicatePolicyMin:
+		return "min"
+	case TimeSeriesDuplicatePolicyMax:
+		return "max"
+	case TimeSeriesDuplicatePolicySum:
+		return "sum"
+	}
+
+	return "none"
+}
+
+// TimeSeriesAggregation is the aggregation type of a compaction rule.
+type TimeSeriesAggregation uint64
+
+// Aggregation types of compaction rules.
+const (
+	TimeSeriesAggregationNone TimeSeriesAggregation = iota
+	TimeSeriesAggregationMin
+	TimeSeriesAggregationMax
+	TimeSeriesAggregationSum
+	TimeSeriesAggregationAvg
+	TimeSeriesAggregationCount
+	TimeSeriesAggregationFirst
+	TimeSeriesAggregationLast
+	TimeSeriesAggregationRange
+	TimeSeriesAggregationStdP
+	TimeSeriesAggregationStdS
+	TimeSeriesAggregationVarP
+	TimeSeriesAggregationVarS
+)
+
+// TimeSeriesHead contains the key and the number of chunks of a time series.
+// It is returned when a time series is read first time.
+type TimeSeriesHead struct {
+	DataKey
+	Length int
+}
+
+// TimeSeriesEntry is returned when a new chunk of a time series is read.
+// Samples of the chunk can be read with Samples.
+type TimeSeriesEntry struct {
+	DataKey
+	TimeSeriesChunk
+	Index  int
+	Length int
+}
+
+// TimeSeriesData is returned when all chunks of a time series are read.
+// ChunkCount is the number of chunks returned as TimeSeriesEntry. Chunks are
+// only kept by ParseDumpPayload, which returns the whole series at once.
+type TimeSeriesData struct {
+	DataKey
+	Retention       time.Duration
+	ChunkSize       uint64
+	Options         uint64
+	LastSample      TimeSeriesSample
+	TotalSamples    uint64
+	DuplicatePolicy TimeSeriesDuplicatePolicy
+	SourceKey       string
+	Labels          map[string]string
+	Rules           []TimeSeriesRule
+	ChunkCount      int
+	Chunks          []TimeSeriesChunk
+}
+
+// TimeSeriesRule is a compaction rule of a time series. Context contains the
+// state of the aggregation of the current bucket.
+type TimeSeriesRule struct {
+	DestKey                string
+	BucketDuration         time.Duration
+	TimestampAlignment     uint64
+	Aggregation            TimeSeriesAggregation
+	Context                []ModuleValue
+	StartCurrentTimeBucket int64
+}
+
+// TimeSeriesChunk is a chunk of samples. Data contains Count samples, which
+// are compressed with Gorilla compression when Compressed is true. The first
+// sample of a compressed chunk is stored in BaseTimestamp and BaseValue.
+type TimeSeriesChunk struct {
+	Compressed    bool
+	BaseTimestamp uint64
+	BaseValue     float64
+	Count         uint64
+	Data          []byte
+}
+
+// TimeSeriesSample is a sample of a time series. Timestamp is in milliseconds.
+type TimeSeriesSample struct {
+	Timestamp uint64
+	Value     float64
+}
+
+// Samples returns an iterator over samples of the chunk.
+func (t *TimeSeriesChunk) Samples() *TimeSeriesSampleIterator {
+	return &TimeSeriesSampleIterator{chunks: []TimeSeriesChunk{*t}}
+}
+
+// Samples returns an iterator over samples of all chunks in Chunks.
+func (t *TimeSeriesData) Samples() *TimeSeriesSampleIterator {
+	return &TimeSeriesSampleIterator{chunks: t.Chunks}
+}
+
+// TimeSeriesSampleIterator iterates over samples of time series chunks.
+// Samples are decoded one at a time from the raw data of chunks.
+type TimeSeriesSampleIterator struct {
+	chunks []TimeSeriesChunk
+	chunk  *TimeSeriesChunk
+	index  uint64
+
+	bits          *timeSeriesBitReader
+	prevTimestamp uint64
+	prevDelta     int64
+	prevValue     uint64
+	prevLeading   uint
+	prevTrailing  uint
+}
+
+// Next returns the next sample. It returns io.EOF when there are no more
+// samples.
+func (t *TimeSeriesSampleIterator) Next() (TimeSeriesSample, error) {
+	for t.chunk == nil || t.index == t.chunk.Count {
+		if len(t.chunks) == 0 {
+			return TimeSeriesSample{}, io.EOF
+		}
+
+		t.chunk = &t.chunks[0]
+		t.chunks = t.chunks[1:]
+		t.index = 0
+		t.bits = &timeSeriesBitReader{data: t.chunk.Data}
+	}
+
+	var (
+		sample TimeSeriesSample
+		err    error
+	)
+
+	if t.chunk.Compressed {
+		sample, err = t.nextCompressed()
+	} else {
+		sample, err = t.nextUncompressed()
+	}
+
+	if err != nil {
+		return sample, err
+	}
+
+	t.index++
+
+	return sample, nil
+}
+
+func (t *TimeSeriesSampleIterator) nextUncompressed() (TimeSeriesSample, error) {
+	offset := t.index * timeSeriesSampleSize
+
+	if offset+timeSeriesSampleSize > uint64(len(t.chunk.Data)) {
+		return TimeSeriesSample{}, io.ErrUnexpectedEOF
+	}
+
+	buf := t.chunk.Data[offset:]
+
+	return TimeSeriesSample{
+		Timestamp: binary.LittleEndian.Uint64(buf),
+		Value:     math.Float64frombits(binary.LittleEndian.Uint64(buf[8:])),
+	}, nil
+}
+
+// https://github.com/RedisTimeSeries/RedisTimeSeries/blob/master/src/gorilla.c
+func (t *TimeSeriesSampleIterator) nextCompressed() (TimeSeriesSample, error) {
+	if t.index == 0 {
+		t.prevTimestamp = t.chunk.BaseTimestamp
+		t.prevDelta = 0
+		t.prevValue = math.Float64bits(t.chunk.BaseValue)
+		t.prevLeading = 32
+		t.prevTrailing = 32
+
+		return TimeSeriesSample{
+			Timestamp: t.chunk.BaseTimestamp,
+			Value:     t.chunk.BaseValue,
+		}, nil
+	}
+
+	deltaOfDelta, err := t.readDeltaOfDelta()
+	if err != nil {
+		return TimeSeriesSample{}, err
+	}
+
+	t.prevDelta += deltaOfDelta
+	t.prevTimestamp += uint64(t.prevDelta)
+
+	if err := t.readValue(); err != nil {
+		return TimeSeriesSample{}, err
+	}
+
+	return TimeSeriesSample{
+		Timestamp: t.prevTimestamp,
+		Value:     math.Float64frombits(t.prevValue),
+	}, nil
+}
+
+func (t *TimeSeriesSampleIterator) readDeltaOfDelta() (int64, error) {
+	var ones int
+
+	// Count the leading 1 bits of the control bits
+	for ones < len(timeSeriesDeltaLengths)-1 {
+		bit, err := t.bits.Read(1)
+		if err != nil {
+			return 0, err
+		}
+
+		if bit == 0 {
+			break
+		}
+
+		ones++
+	}
+
+	length := timeSeriesDeltaLengths[ones]
+
+	if length == 0 {
+		return 0, nil
+	}
+
+	value, err := t.bits.Read(length)
+	if err != nil {
+		return 0, err
+	}
+
+	// Sign extension
+	if length < 64 && value&(1<<(length-1)) != 0 {
+		value |= math.MaxUint64 << length
+	}
+
+	return int64(value), nil
+}
+
+func (t *TimeSeriesSampleIterator) readValue() error {
+	changed, err := t.bits.Read(1)
+	if err != nil || changed == 0 {
+		return err
+	}
+
+	control, err := t.bits.Read(1)
+	if err != nil {
+		return err
+	}
+
+	if control == 1 {
+		leading, err := t.bits.Read(timeSeriesDoubleLeading)
+		if err != nil {
+			return err
+		}
+
+		blockSize, err := t.bits.Read(timeSeriesDoubleBlockSize)
+		if err != nil {
+			return err
+		}
+
+		t.prevLeading = uint(leading)
+		t.prevTrailing = 64 - t.prevLeading - uint(blockSize+timeSeriesDoubleBlockAdjust)
+	}
+
+	xor, err := t.bits.Read(64 - t.prevLeading - t.prevTrailing)
+	if err != nil {
+		return err
+	}
+
+	t.prevValue ^= xor << t.prevTrailing
+
+	return nil
+}
+
+// timeSeriesBitReader reads bits from the least significant bit of each byte.
+type timeSeriesBitReader struct {
+	data   []byte
+	offset uint
+}
+
+func (t *timeSeriesBitReader) Read(length uint) (uint64, error) {
+	if t.offset+length > uint(len(t.data))*8 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	var value uint64
+
+	for i := uint(0); i < length; i++ {
+		pos := t.offset + i
+		value |= uint64(t.data[pos/8]>>(pos%8)&1) << i
+	}
+
+	t.offset += length
+
+	return value, nil
+}
+
+type timeSeriesDecoder struct{}
+
+// https://github.com/RedisTimeSeries/RedisTimeSeries/blob/master/src/rdb.c
+// nolint: gocognit, gocyclo
+func (timeSeriesDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion < timeSeriesUncompressedVersion || encodingVersion > timeSeriesEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisTimeSeriesModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	ts := &TimeSeriesData{DataKey: key}
+
+	// key name
+	if _, err := r.ReadString(); err != nil {
+		return nil, err
+	}
+
+	retention, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	ts.Retention = time.Duration(retention) * time.Millisecond
+
+	if ts.ChunkSize, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	// The chunk size was the number of samples in early versions
+	if encodingVersion < timeSeriesChunkSizeVersion {
+		ts.ChunkSize *= timeSeriesSampleSize
+	}
+
+	if ts.Options, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if ts.LastSample.Timestamp, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if ts.LastSample.Value, err = r.ReadDouble(); err != nil {
+		return nil, err
+	}
+
+	if ts.TotalSamples, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if encodingVersion >= timeSeriesDuplicatePolicyVersion {
+		policy, err := r.ReadUnsigned()
+		if err != nil {
+			return nil, err
+		}
+
+		ts.DuplicatePolicy = TimeSeriesDuplicatePolicy(policy)
+	}
+
+	hasSourceKey, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	if hasSourceKey != 0 {
+		if ts.SourceKey, err = r.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+
+	labelCount, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	ts.Labels = make(map[string]string, labelCount)
+
+	for i := uint64(0); i < labelCount; i++ {
+		name, err := r.ReadString()
+		if err != nil {
+			return nil, err
+		}
+
+		if ts.Labels[name], err = r.ReadString(); err != nil {
+			return nil, err
+		}
+	}
+
+	ruleCount, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < ruleCount; i++ {
+		rule, err := readTimeSeriesRule(r, encodingVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.Rules = append(ts.Rules, *rule)
+	}
+
+	chunkCount, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	ts.ChunkCount = int(chunkCount)
+
+	return &timeSeriesIterator{reader: r, data: ts}, nil
+}
+
+// timeSeriesIterator reads chunks of a time series one at a time, so chunks
+// don't have to be held in memory until the whole series is read.
+type timeSeriesIterator struct {
+	reader *ModuleReader
+	data   *TimeSeriesData
+
+	index       int
+	initialized bool
+	done        bool
+}
+
+func (t *timeSeriesIterator) Next() (interface{}, error) {
+	if t.done {
+		return nil, io.EOF
+	}
+
+	if !t.initialized {
+		t.initialized = true
+
+		return &TimeSeriesHead{
+			DataKey: t.data.DataKey,
+			Length:  t.data.ChunkCount,
+		}, nil
+	}
+
+	if t.index == t.data.ChunkCount {
+		t.done = true
+
+		return t.data, nil
+	}
+
+	var (
+		chunk *TimeSeriesChunk
+		err   error
+	)
+
+	if t.data.Options&timeSeriesOptionUncompressed != 0 {
+		chunk, err = readTimeSeriesUncompressedChunk(t.reader)
+	} else {
+		chunk, err = readTimeSeriesCompressedChunk(t.reader)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &TimeSeriesEntry{
+		DataKey:         t.data.DataKey,
+		TimeSeriesChunk: *chunk,
+		Index:           t.index,
+		Length:          t.data.ChunkCount,
+	}
+
+	t.index++
+
+	return entry, nil
+}
+
+func (*timeSeriesIterator) moduleIterator() {}
+
+func readTimeSeriesRule(r *ModuleReader, encodingVersion int) (*TimeSeriesRule, error) {
+	var err error
+
+	rule := new(TimeSeriesRule)
+
+	if rule.DestKey, err = r.ReadString(); err != nil {
+		return nil, err
+	}
+
+	bucketDuration, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	rule.BucketDuration = time.Duration(bucketDuration) * time.Millisecond
+
+	if encodingVersion >= timeSeriesAlignmentVersion {
+		if rule.TimestampAlignment, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	aggregation, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Aggregation = TimeSeriesAggregation(aggregation)
+
+	if rule.Context, err = readTimeSeriesAggregationContext(r, rule.Aggregation, encodingVersion); err != nil {
+		return nil, err
+	}
+
+	if rule.StartCurrentTimeBucket, err = r.ReadSigned(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// https://github.com/RedisTimeSeries/RedisTimeSeries/blob/master/src/compaction.c
+func readTimeSeriesAggregationContext(r *ModuleReader, aggregation TimeSeriesAggregation, encodingVersion int) ([]ModuleValue, error) {
+	var types []ModuleValueType
+
+	switch aggregation {
+	case TimeSeriesAggregationMin, TimeSeriesAggregationMax, TimeSeriesAggregationRange:
+		types = []ModuleValueType{ModuleValueDouble, ModuleValueDouble}
+
+		if encodingVersion >= timeSeriesDuplicatePolicyVersion {
+			types = append(types, ModuleValueUInt)
+		}
+
+	case TimeSeriesAggregationSum, TimeSeriesAggregationCount,
+		TimeSeriesAggregationFirst, TimeSeriesAggregationLast:
+		types = []ModuleValueType{ModuleValueDouble}
+
+		if encodingVersion >= timeSeriesDuplicatePolicyVersion {
+			types = append(types, ModuleValueUInt)
+		}
+
+	case TimeSeriesAggregationAvg:
+		types = []ModuleValueType{ModuleValueDouble, ModuleValueDouble}
+
+		if encodingVersion >= timeSeriesOverflowVersion {
+			types = append(types, ModuleValueUInt)
+		}
+
+	case TimeSeriesAggregationStdP, TimeSeriesAggregationStdS,
+		TimeSeriesAggregationVarP, TimeSeriesAggregationVarS:
+		types = []ModuleValueType{ModuleValueDouble, ModuleValueDouble, ModuleValueUInt}
+
+	default:
+		return nil, TimeSeriesAggregationError{Aggregation: uint64(aggregation)}
+	}
+
+	values := make([]ModuleValue, len(types))
+
+	for i, t := range types {
+		value, err := r.ReadValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if value.Type != t {
+			return nil, ModuleOpcodeError{Actual: int(value.Type), Expected: int(t)}
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func readTimeSeriesUncompressedChunk(r *ModuleReader) (*TimeSeriesChunk, error) {
+	var err error
+
+	chunk := new(TimeSeriesChunk)
+
+	if chunk.BaseTimestamp, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	if chunk.Count, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	// size
+	if _, err := r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	data, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.Data = []byte(data)
+
+	return chunk, nil
+}
+
+func readTimeSeriesCompressedChunk(r *ModuleReader) (*TimeSeriesChunk, error) {
+	chunk := &TimeSeriesChunk{Compressed: true}
+
+	// size
+	if _, err := r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if chunk.Count, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	// idx
+	if _, err := r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	baseValue, err := r.ReadUnsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.BaseValue = math.Float64frombits(baseValue)
+
+	if chunk.BaseTimestamp, err = r.ReadUnsigned(); err != nil {
+		return nil, err
+	}
+
+	// prevTimestamp, prevTimestampDelta, prevValue, prevLeading, prevTrailing
+	for i := 0; i < 5; i++ {
+		if _, err := r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.Data = []byte(data)
+
+	return chunk, nil
+}
@@ -0,0 +1,162 @@
+package rdb
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeSeriesData", func() {
+	var (
+		series map[string]*TimeSeriesData
+		chunks map[string][]TimeSeriesChunk
+	)
+
+	BeforeEach(func() {
+		file, err := os.Open("fixtures/redis_time_series.rdb")
+		Expect(err).NotTo(HaveOccurred())
+
+		defer file.Close()
+
+		series = map[string]*TimeSeriesData{}
+		chunks = map[string][]TimeSeriesChunk{}
+		parser := NewParser(file)
+
+		for {
+			data, err := parser.Next()
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+
+			switch v := data.(type) {
+			case *TimeSeriesHead:
+				Expect(chunks).NotTo(HaveKey(v.Key))
+				chunks[v.Key] = nil
+
+			case *TimeSeriesEntry:
+				Expect(v.Index).To(Equal(len(chunks[v.Key])))
+				chunks[v.Key] = append(chunks[v.Key], v.TimeSeriesChunk)
+
+			case *TimeSeriesData:
+				Expect(v.ChunkCount).To(Equal(len(chunks[v.Key])))
+				Expect(v.Chunks).To(BeNil())
+				series[v.Key] = v
+			}
+		}
+	})
+
+	readSamples := func(iter *TimeSeriesSampleIterator) []TimeSeriesSample {
+		var samples []TimeSeriesSample
+
+		for {
+			sample, err := iter.Next()
+
+			if errors.Is(err, io.EOF) {
+				return samples
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			samples = append(samples, sample)
+		}
+	}
+
+	readChunkSamples := func(key string) []TimeSeriesSample {
+		var samples []TimeSeriesSample
+
+		for i := range chunks[key] {
+			samples = append(samples, readSamples(chunks[key][i].Samples())...)
+		}
+
+		return samples
+	}
+
+	It("should read samples of compressed chunks", func() {
+		Expect(series["ts"].TotalSamples).To(Equal(uint64(9)))
+		Expect(readChunkSamples("ts")).To(Equal([]TimeSeriesSample{
+			{Timestamp: 1000, Value: 1},
+			{Timestamp: 2000, Value: 1},
+			{Timestamp: 3000, Value: 2.5},
+			{Timestamp: 4000, Value: 2.5},
+			{Timestamp: 4500, Value: -3.25},
+			{Timestamp: 10000, Value: 100},
+			{Timestamp: 20000, Value: 100},
+			{Timestamp: 20010, Value: 100.5},
+			{Timestamp: 1 << 40, Value: 0.1},
+		}))
+	})
+
+	It("should read samples of uncompressed chunks", func() {
+		Expect(readChunkSamples("ts_raw")).To(Equal([]TimeSeriesSample{
+			{Timestamp: 1, Value: 1.5},
+			{Timestamp: 2, Value: 2.5},
+			{Timestamp: 3, Value: -1},
+		}))
+	})
+
+	It("should return io.EOF when there are no chunks", func() {
+		Expect(series["ts_avg"].ChunkCount).To(BeZero())
+		Expect(readSamples(series["ts_avg"].Samples())).To(BeEmpty())
+	})
+
+	It("should return error when the chunk is truncated", func() {
+		chunk := chunks["ts"][0]
+		chunk.Data = chunk.Data[:1]
+		iter := chunk.Samples()
+
+		_, err := iter.Next()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = iter.Next()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	It("should keep chunks of DUMP payloads", func() {
+		file, err := os.Open("fixtures/redis_time_series.rdb")
+		Expect(err).NotTo(HaveOccurred())
+
+		defer file.Close()
+
+		parser := NewParser(file)
+		parser.RawValues = true
+
+		for {
+			data, err := parser.Next()
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+
+			raw, ok := data.(*RawData)
+			if !ok {
+				continue
+			}
+
+			value, err := ParseDumpPayload(DumpPayload(raw.Value, parser.Version()))
+			Expect(err).NotTo(HaveOccurred())
+
+			ts, ok := value.(*TimeSeriesData)
+			Expect(ok).To(BeTrue())
+			Expect(ts.Chunks).To(Equal(chunks[raw.Key]))
+			Expect(readSamples(ts.Samples())).To(Equal(readChunkSamples(raw.Key)))
+		}
+	})
+})
+
+var _ = Describe("timeSeriesDecoder", func() {
+	It("should return UnsupportedDataTypeError for unknown encoding versions", func() {
+		_, err := timeSeriesDecoder{}.DecodeModule(nil, DataKey{}, timeSeriesEncodingVersion+1)
+		Expect(err).To(Equal(UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisTimeSeriesModule,
+			EncodingVersion: timeSeriesEncodingVersion + 1,
+		}))
+	})
+})
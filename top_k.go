@@ -0,0 +1,136 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	redisBloomTopKModule          = "TopK-TYPE"
+	redisBloomTopKEncodingVersion = 0
+
+	// Size of Bucket and HeapBucket structs in RedisBloom.
+	topKBucketSize     = 8
+	topKHeapBucketSize = 24
+)
+
+// TopK represents a top-k list implemented by RedisBloom.
+type TopK struct {
+	DataKey
+	K     uint64
+	Width uint64
+	Depth uint64
+	Decay float64
+	// Buckets contains Depth rows of Width buckets.
+	Buckets []TopKBucket
+	// Items contains the heavy hitters sorted by count in descending order, in
+	// the same way as TOPK.LIST.
+	Items []TopKItem
+}
+
+// TopKBucket is a bucket of a top-k list.
+type TopKBucket struct {
+	Fingerprint uint32
+	Count       uint32
+}
+
+// TopKItem is a heavy hitter of a top-k list.
+type TopKItem struct {
+	Item        string
+	Fingerprint uint32
+	Count       uint32
+}
+
+// Query returns true if the item is one of the heavy hitters, in the same way
+// as TOPK.QUERY.
+func (t *TopK) Query(item []byte) bool {
+	for _, v := range t.Items {
+		if v.Item == string(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type topKDecoder struct{}
+
+// https://github.com/RedisBloom/RedisBloom/blob/21a2620e75873353fead8c5d70950d3791e36b18/src/rm_topk.c
+func (topKDecoder) DecodeModule(r *ModuleReader, key DataKey, encodingVersion int) (interface{}, error) {
+	if encodingVersion > redisBloomTopKEncodingVersion {
+		return nil, UnsupportedDataTypeError{
+			DataType:        typeModule2,
+			ModuleName:      redisBloomTopKModule,
+			EncodingVersion: encodingVersion,
+		}
+	}
+
+	var err error
+
+	topK := &TopK{DataKey: key}
+
+	for _, field := range []*uint64{&topK.K, &topK.Width, &topK.Depth} {
+		if *field, err = r.ReadUnsigned(); err != nil {
+			return nil, err
+		}
+	}
+
+	if topK.Decay, err = r.ReadDouble(); err != nil {
+		return nil, err
+	}
+
+	buckets, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	topK.Buckets = make([]TopKBucket, len(buckets)/topKBucketSize)
+
+	for i := range topK.Buckets {
+		buf := []byte(buckets[i*topKBucketSize : (i+1)*topKBucketSize])
+		topK.Buckets[i] = TopKBucket{
+			Fingerprint: binary.LittleEndian.Uint32(buf[0:]),
+			Count:       binary.LittleEndian.Uint32(buf[4:]),
+		}
+	}
+
+	heap, err := r.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < topK.K; i++ {
+		item, err := r.ReadString()
+		if err != nil {
+			return nil, err
+		}
+
+		if (i+1)*topKHeapBucketSize > uint64(len(heap)) {
+			continue
+		}
+
+		buf := []byte(heap[i*topKHeapBucketSize : (i+1)*topKHeapBucketSize])
+
+		// Skip empty slots whose item pointer is NULL
+		if binary.LittleEndian.Uint64(buf[8:]) == 0 {
+			continue
+		}
+
+		// The item may be saved with a trailing NULL character
+		if itemLength := int(binary.LittleEndian.Uint32(buf[4:])); itemLength < len(item) {
+			item = item[:itemLength]
+		}
+
+		topK.Items = append(topK.Items, TopKItem{
+			Item:        item,
+			Fingerprint: binary.LittleEndian.Uint32(buf[0:]),
+			Count:       binary.LittleEndian.Uint32(buf[16:]),
+		})
+	}
+
+	sort.SliceStable(topK.Items, func(i, j int) bool {
+		return topK.Items[i].Count > topK.Items[j].Count
+	})
+
+	return topK, nil
+}
@@ -29,6 +29,11 @@ type collectionMapper interface {
 	MapSlice(*collectionSlice) (interface{}, error)
 }
 
+// Header contains the version of the RDB file.
+type Header struct {
+	Version int
+}
+
 type Aux struct {
 	Key   string
 	Value string
@@ -39,11 +44,25 @@ type DatabaseSize struct {
 	Expire int
 }
 
+// SlotInfo contains the number of keys and keys with expiry in a cluster slot.
+// It is saved before keys of the slot when cluster mode is enabled.
+type SlotInfo struct {
+	ID          int
+	Size        int
+	ExpiresSize int
+}
+
 // DataKey contains the database, the key and the expiry of data.
 type DataKey struct {
 	Database int
 	Key      string
 	Expiry   *time.Time
+	// Idle is the LRU idle time, which is only saved when maxmemory-policy is
+	// an LRU policy.
+	Idle *time.Duration
+	// Freq is the LFU frequency counter, which is only saved when
+	// maxmemory-policy is an LFU policy.
+	Freq *uint8
 }
 
 // Expired returns true if the key is expired.
@@ -60,15 +79,3 @@ type StringData struct {
 	DataKey
 	Value string
 }
-
-// BloomFilter represents a bloom filter data structure implemented by RedisBloom.
-// At present, It only contains data key, but does not store the actual data values.
-type BloomFilter struct {
-	DataKey
-}
-
-// CuckooFilter represents a cuckoo filter data structure implemented by RedisBloom.
-// At present, It only contains data key, but does not store the actual data values.
-type CuckooFilter struct {
-	DataKey
-}
@@ -17,3 +17,13 @@ var _ = Describe("DataKey", func() {
 		Entry("before now", timePtr(time.Now().Add(-time.Minute)), true),
 	)
 })
+
+var _ = Describe("HashValue", func() {
+	DescribeTable("Expired", func(expiry *time.Time, expected bool) {
+		Expect(HashValue{Expiry: expiry}.Expired()).To(Equal(expected))
+	},
+		Entry("nil", nil, false),
+		Entry("after now", timePtr(time.Now().Add(time.Minute)), false),
+		Entry("before now", timePtr(time.Now().Add(-time.Minute)), true),
+	)
+})
@@ -106,7 +106,11 @@ func readMillisecondsTime(r byteReader) (*time.Time, error) {
 		return nil, fmt.Errorf("readMillisecondsTime error: %w", err)
 	}
 
-	return timePtr(time.Unix(0, int64(value)*int64(time.Millisecond)).UTC()), nil
+	return millisecondsToTime(int64(value)), nil
+}
+
+func millisecondsToTime(value int64) *time.Time {
+	return timePtr(time.Unix(0, value*int64(time.Millisecond)).UTC())
 }
 
 func readSecondsTime(r byteReader) (*time.Time, error) {
@@ -122,6 +126,10 @@ func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
 func readStringByLength(r byteReader, length int) (string, error) {
 	buf, err := r.ReadBytes(length)
 	if err != nil {
@@ -374,41 +382,126 @@ func checkRdbModuleOpCode(r byteReader, expected int) error {
 	return nil
 }
 
-func redisModuleReadUnsigned(r byteReader) (int, error) {
-	if err := checkRdbModuleOpCode(r, rdbModuleOpcodeUInt); err != nil {
-		return 0, err
-	}
+func appendUint16(buf []byte, v uint16) []byte {
+	var b [2]byte
 
-	val, err := readLength(r)
-	if err != nil {
-		return 0, err
+	binary.LittleEndian.PutUint16(b[:], v)
+
+	return append(buf, b[:]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+
+	binary.LittleEndian.PutUint32(b[:], v)
+
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+
+	binary.LittleEndian.PutUint64(b[:], v)
+
+	return append(buf, b[:]...)
+}
+
+func appendUint64BE(buf []byte, v uint64) []byte {
+	var b [8]byte
+
+	binary.BigEndian.PutUint64(b[:], v)
+
+	return append(buf, b[:]...)
+}
+
+func appendLength(buf []byte, length uint64) []byte {
+	switch {
+	case length < 1<<6:
+		return append(buf, byte(length))
+
+	case length < 1<<14:
+		return append(buf, byte(length>>8)|len14Bit<<6, byte(length))
+
+	case length <= math.MaxUint32:
+		buf = append(buf, len32Bit, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+
+		return buf
 	}
 
-	return val, nil
+	return appendUint64BE(append(buf, len64Bit), length)
 }
 
-func redisModuleReadDouble(r byteReader) (uint64, error) {
-	if err := checkRdbModuleOpCode(r, rdbModuleOpcodeDouble); err != nil {
-		return 0, err
+func appendMillisecondsTime(buf []byte, t time.Time) []byte {
+	return appendUint64(buf, uint64(timeToMilliseconds(t)))
+}
+
+func timeToMilliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// parseStringInteger returns the integer value of s when s is the canonical
+// form of the integer, which is how Redis decides whether a string can be
+// saved as an integer.
+func parseStringInteger(s string) (int64, bool) {
+	if len(s) == 0 || len(s) > 20 {
+		return 0, false
 	}
 
-	scoreBytes, err := readUint64(r)
-	if err != nil {
-		return 0, err
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || strconv.FormatInt(v, 10) != s {
+		return 0, false
 	}
 
-	return scoreBytes, nil
+	return v, true
 }
 
-func redisModuleReadStringBuffer(r byteReader) (string, error) {
-	if err := checkRdbModuleOpCode(r, rdbModuleOpcodeString); err != nil {
-		return "", err
+// appendStringEncoding appends a string which is saved as an integer when
+// possible, or compressed with LZF when compress is true and it saves space.
+func appendStringEncoding(buf []byte, s []byte, compress bool) []byte {
+	if len(s) <= 11 {
+		if v, ok := parseStringInteger(string(s)); ok {
+			switch {
+			case v >= math.MinInt8 && v <= math.MaxInt8:
+				return append(buf, lenEncVal<<6|encInt8, byte(v))
+
+			case v >= math.MinInt16 && v <= math.MaxInt16:
+				return appendUint16(append(buf, lenEncVal<<6|encInt16), uint16(v))
+
+			case v >= math.MinInt32 && v <= math.MaxInt32:
+				return appendUint32(append(buf, lenEncVal<<6|encInt32), uint32(v))
+			}
+		}
 	}
 
-	value, err := readString(r)
-	if err != nil {
-		return "", err
+	if compress && len(s) > 20 {
+		compressed := make([]byte, len(s)-4)
+
+		if n, err := lzf.Compress(s, compressed); err == nil && n > 0 {
+			buf = appendLength(append(buf, lenEncVal<<6|encLZF), uint64(n))
+			buf = appendLength(buf, uint64(len(s)))
+
+			return append(buf, compressed[:n]...)
+		}
+	}
+
+	return append(appendLength(buf, uint64(len(s))), s...)
+}
+
+func appendFloat(buf []byte, v float64) []byte {
+	switch {
+	case math.IsNaN(v):
+		return append(buf, 253)
+	case math.IsInf(v, 1):
+		return append(buf, 254)
+	case math.IsInf(v, -1):
+		return append(buf, 255)
 	}
 
-	return value, nil
+	s := strconv.FormatFloat(v, 'g', 17, 64)
+
+	return append(append(buf, byte(len(s))), s...)
+}
+
+func appendBinaryDouble(buf []byte, v float64) []byte {
+	return appendUint64(buf, math.Float64bits(v))
 }
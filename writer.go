@@ -0,0 +1,826 @@
+package rdb
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tommy351/rdb-go/internal/convert"
+)
+
+const (
+	// quickListNodeEntries is the maximum number of entries in a quicklist node.
+	quickListNodeEntries = 128
+
+	// streamNodeEntries is the maximum number of entries in a stream node, which
+	// is the default value of stream-node-max-entries.
+	streamNodeEntries = 100
+)
+
+// Encoding is the encoding of values written by Writer.
+type Encoding int
+
+const (
+	// EncodingPlain saves each element of a value as a string.
+	EncodingPlain Encoding = iota
+	// EncodingZipMap saves a hash as a zipmap.
+	EncodingZipMap
+	// EncodingZipList saves a list, a sorted set or a hash as a ziplist.
+	EncodingZipList
+	// EncodingIntSet saves a set of integers as an intset.
+	EncodingIntSet
+	// EncodingQuickList saves a list as a quicklist of ziplists.
+	EncodingQuickList
+	// EncodingListPack saves a set, a sorted set or a hash as a listpack, and a
+	// list as a quicklist of listpacks.
+	EncodingListPack
+)
+
+// nolint: gochecknoglobals
+var encodingNames = map[Encoding]string{
+	EncodingPlain:     "plain",
+	EncodingZipMap:    "zipmap",
+	EncodingZipList:   "ziplist",
+	EncodingIntSet:    "intset",
+	EncodingQuickList: "quicklist",
+	EncodingListPack:  "listpack",
+}
+
+func (e Encoding) String() string {
+	if name, ok := encodingNames[e]; ok {
+		return name
+	}
+
+	return "encoding " + strconv.Itoa(int(e))
+}
+
+// Writer writes a RDB dump file. WriteHeader must be called first and Close
+// must be called last, which writes the EOF opcode and the checksum.
+//
+// The database of a key is selected automatically when it is different from
+// the database of the previous key. Expiries of members set by EXPIREMEMBER in
+// KeyDB are not written.
+type Writer struct {
+	// Compress enables LZF compression of strings longer than 20 bytes. A
+	// string is only compressed when it saves space.
+	Compress bool
+
+	writer   io.Writer
+	version  int
+	checksum uint64
+	db       int
+}
+
+// NewWriter returns a new Writer which writes a RDB file of the given version
+// to w.
+func NewWriter(w io.Writer, version int) *Writer {
+	return &Writer{
+		writer:  w,
+		version: version,
+		db:      -1,
+	}
+}
+
+func (w *Writer) write(buf []byte) error {
+	if _, err := w.writer.Write(buf); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+
+	w.checksum = updateCRC64(w.checksum, buf)
+
+	return nil
+}
+
+func (w *Writer) encoder() *valueEncoder {
+	return &valueEncoder{version: w.version, compress: w.Compress}
+}
+
+func (w *Writer) checkOpCode(opCode byte) error {
+	if version, ok := opCodeMinVersions[opCode]; ok && w.version < version {
+		return UnsupportedOpCodeError{OpCode: opCode, Version: w.version}
+	}
+
+	return nil
+}
+
+// WriteHeader writes the magic string and the version.
+func (w *Writer) WriteHeader() error {
+	if w.version < minVersion || w.version > maxVersion {
+		return UnsupportedVersionError{Version: w.version}
+	}
+
+	return w.write([]byte(fmt.Sprintf("%s%04d", magicString, w.version)))
+}
+
+// WriteAux writes an aux field.
+func (w *Writer) WriteAux(aux *Aux) error {
+	e := w.encoder()
+	buf := e.appendString([]byte{opCodeAux}, aux.Key)
+
+	return w.write(e.appendString(buf, aux.Value))
+}
+
+// WriteModuleAux writes auxiliary data of a module.
+func (w *Writer) WriteModuleAux(aux *ModuleAux) error {
+	if err := w.checkOpCode(opCodeModuleAux); err != nil {
+		return err
+	}
+
+	id, err := EncodeModuleID(aux.ModuleName, aux.EncodingVersion)
+	if err != nil {
+		return err
+	}
+
+	buf := appendLength([]byte{opCodeModuleAux}, id)
+	buf = appendLength(buf, rdbModuleOpcodeUInt)
+	buf = appendLength(buf, uint64(aux.When))
+
+	if buf, err = w.encoder().appendModuleValues(buf, aux.Values); err != nil {
+		return err
+	}
+
+	return w.write(buf)
+}
+
+// WriteFunction writes a function library. Only the code is saved, so the
+// engine and the name must match the shebang line of the code.
+func (w *Writer) WriteFunction(lib *FunctionLibrary) error {
+	if err := w.checkOpCode(opCodeFunction2); err != nil {
+		return err
+	}
+
+	return w.write(w.encoder().appendString([]byte{opCodeFunction2}, lib.Code))
+}
+
+// WriteSelectDB selects the database of the following keys.
+func (w *Writer) WriteSelectDB(db int) error {
+	if err := w.write(appendLength([]byte{opCodeSelectDB}, uint64(db))); err != nil {
+		return err
+	}
+
+	w.db = db
+
+	return nil
+}
+
+// WriteDatabaseSize writes the number of keys and keys with expiry in the
+// current database, which is saved after the database is selected.
+func (w *Writer) WriteDatabaseSize(size *DatabaseSize) error {
+	buf := appendLength([]byte{opCodeResizeDB}, uint64(size.Size))
+
+	return w.write(appendLength(buf, uint64(size.Expire)))
+}
+
+// WriteSlotInfo writes the number of keys and keys with expiry in a cluster
+// slot.
+func (w *Writer) WriteSlotInfo(info *SlotInfo) error {
+	if err := w.checkOpCode(opCodeSlotInfo); err != nil {
+		return err
+	}
+
+	buf := appendLength([]byte{opCodeSlotInfo}, uint64(info.ID))
+	buf = appendLength(buf, uint64(info.Size))
+
+	return w.write(appendLength(buf, uint64(info.ExpiresSize)))
+}
+
+// WriteString writes a string.
+func (w *Writer) WriteString(data *StringData) error {
+	return w.writeData(&data.DataKey, data, EncodingPlain)
+}
+
+// WriteList writes a list with the given encoding, which is one of
+// EncodingPlain, EncodingZipList, EncodingQuickList and EncodingListPack.
+func (w *Writer) WriteList(data *ListData, enc Encoding) error {
+	return w.writeData(&data.DataKey, data, enc)
+}
+
+// WriteSet writes a set with the given encoding, which is one of EncodingPlain,
+// EncodingIntSet and EncodingListPack. Values of an intset are sorted.
+func (w *Writer) WriteSet(data *SetData, enc Encoding) error {
+	return w.writeData(&data.DataKey, data, enc)
+}
+
+// WriteSortedSet writes a sorted set with the given encoding, which is one of
+// EncodingPlain, EncodingZipList and EncodingListPack. Values are written in
+// the given order.
+func (w *Writer) WriteSortedSet(data *SortedSetData, enc Encoding) error {
+	return w.writeData(&data.DataKey, data, enc)
+}
+
+// WriteHash writes a hash with the given encoding, which is one of
+// EncodingPlain, EncodingZipMap, EncodingZipList and EncodingListPack. Fields
+// are sorted. Hashes with field expiries can only be written as EncodingPlain
+// or EncodingListPack.
+func (w *Writer) WriteHash(data *HashData, enc Encoding) error {
+	return w.writeData(&data.DataKey, data, enc)
+}
+
+// WriteStream writes a stream. The stream type is chosen by the version.
+func (w *Writer) WriteStream(data *StreamData) error {
+	return w.writeData(&data.DataKey, data, EncodingPlain)
+}
+
+// WriteModule writes values of a module.
+func (w *Writer) WriteModule(data *ModuleData) error {
+	return w.writeData(&data.DataKey, data, EncodingPlain)
+}
+
+// Close writes the EOF opcode and the checksum, which is only saved since
+// version 5. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.write([]byte{opCodeEOF}); err != nil {
+		return err
+	}
+
+	if w.version < minChecksumVersion {
+		return nil
+	}
+
+	return w.write(appendUint64(nil, w.checksum))
+}
+
+func (w *Writer) writeData(key *DataKey, data interface{}, enc Encoding) error {
+	dataType, value, err := w.encoder().encode(data, enc)
+	if err != nil {
+		return fmt.Errorf("failed to encode key %q: %w", key.Key, err)
+	}
+
+	if key.Database != w.db {
+		if err := w.WriteSelectDB(key.Database); err != nil {
+			return err
+		}
+	}
+
+	buf, err := w.appendKey(nil, key)
+	if err != nil {
+		return err
+	}
+
+	buf = w.encoder().appendString(append(buf, dataType), key.Key)
+
+	return w.write(append(buf, value...))
+}
+
+func (w *Writer) appendKey(buf []byte, key *DataKey) ([]byte, error) {
+	if key.Expiry != nil {
+		// Expiries in milliseconds are saved since version 3
+		if w.version < 3 {
+			buf = appendUint32(append(buf, opCodeExpireTime), uint32(key.Expiry.Unix()))
+		} else {
+			buf = appendMillisecondsTime(append(buf, opCodeExpireTimeMS), *key.Expiry)
+		}
+	}
+
+	if key.Idle != nil {
+		if err := w.checkOpCode(opCodeIdle); err != nil {
+			return nil, err
+		}
+
+		buf = appendLength(append(buf, opCodeIdle), uint64(*key.Idle/time.Second))
+	}
+
+	if key.Freq != nil {
+		if err := w.checkOpCode(opCodeFreq); err != nil {
+			return nil, err
+		}
+
+		buf = append(buf, opCodeFreq, *key.Freq)
+	}
+
+	return buf, nil
+}
+
+// valueEncoder encodes values in the format read by Parser.readData.
+type valueEncoder struct {
+	version  int
+	compress bool
+}
+
+func (e *valueEncoder) appendString(buf []byte, s string) []byte {
+	return appendStringEncoding(buf, []byte(s), e.compress)
+}
+
+// encode returns the data type and the encoded value of data. An error is
+// returned when the data type doesn't exist in the RDB version.
+func (e *valueEncoder) encode(data interface{}, enc Encoding) (byte, []byte, error) {
+	dataType, value, err := e.encodeValue(data, enc)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if version, ok := dataTypeMinVersions[dataType]; ok && e.version < version {
+		return 0, nil, e.dataTypeError(data, dataType, enc)
+	}
+
+	return dataType, value, nil
+}
+
+func (e *valueEncoder) dataTypeError(data interface{}, dataType byte, enc Encoding) error {
+	var name string
+
+	switch v := data.(type) {
+	case *ListData:
+		name = "list"
+	case *SetData:
+		name = "set"
+	case *SortedSetData:
+		name = "sorted set"
+	case *HashData:
+		name = "hash"
+
+		if len(v.FieldExpiry) > 0 {
+			name = "hash with field expiry"
+		}
+	default:
+		// Streams and modules can't be saved in other data types
+		return UnsupportedDataTypeError{DataType: dataType}
+	}
+
+	return UnsupportedEncodingError{DataType: name, Encoding: enc, Version: e.version}
+}
+
+func (e *valueEncoder) encodeValue(data interface{}, enc Encoding) (byte, []byte, error) {
+	switch v := data.(type) {
+	case *StringData:
+		return typeString, e.appendString(nil, v.Value), nil
+	case *ListData:
+		return e.encodeList(v.Value, enc)
+	case *SetData:
+		return e.encodeSet(v.Value, enc)
+	case *SortedSetData:
+		return e.encodeSortedSet(v.Value, enc)
+	case *HashData:
+		return e.encodeHash(v, enc)
+	case *StreamData:
+		return e.encodeStream(v)
+	case *ModuleData:
+		return e.encodeModule(v)
+	}
+
+	return 0, nil, fmt.Errorf("unsupported data %T", data)
+}
+
+func (e *valueEncoder) encodeList(values []string, enc Encoding) (byte, []byte, error) {
+	switch enc {
+	case EncodingPlain:
+		return typeList, e.appendStrings(appendLength(nil, uint64(len(values))), values), nil
+
+	case EncodingZipList:
+		return typeListZipList, e.appendString(nil, string(encodeZipList(values))), nil
+
+	case EncodingQuickList:
+		nodes := splitValues(values, quickListNodeEntries)
+		buf := appendLength(nil, uint64(len(nodes)))
+
+		for _, node := range nodes {
+			buf = e.appendString(buf, string(encodeZipList(node)))
+		}
+
+		return typeListQuickList, buf, nil
+
+	case EncodingListPack:
+		nodes := splitValues(values, quickListNodeEntries)
+		buf := appendLength(nil, uint64(len(nodes)))
+
+		for _, node := range nodes {
+			buf = appendLength(buf, quickListNodeContainerPacked)
+			buf = e.appendString(buf, string(encodeListPack(node)))
+		}
+
+		return typeListQuickList2, buf, nil
+	}
+
+	return 0, nil, UnsupportedEncodingError{DataType: "list", Encoding: enc}
+}
+
+func (e *valueEncoder) encodeSet(values []string, enc Encoding) (byte, []byte, error) {
+	switch enc {
+	case EncodingPlain:
+		return typeSet, e.appendStrings(appendLength(nil, uint64(len(values))), values), nil
+
+	case EncodingIntSet:
+		ints := make([]int64, len(values))
+
+		for i, value := range values {
+			v, ok := parseStringInteger(value)
+			if !ok {
+				return 0, nil, IntSetValueError{Value: value}
+			}
+
+			ints[i] = v
+		}
+
+		sort.Slice(ints, func(i, j int) bool {
+			return ints[i] < ints[j]
+		})
+
+		return typeSetIntSet, e.appendString(nil, string(encodeIntSet(ints))), nil
+
+	case EncodingListPack:
+		return typeSetListPack, e.appendString(nil, string(encodeListPack(values))), nil
+	}
+
+	return 0, nil, UnsupportedEncodingError{DataType: "set", Encoding: enc}
+}
+
+func (e *valueEncoder) encodeSortedSet(values []SortedSetValue, enc Encoding) (byte, []byte, error) {
+	switch enc {
+	case EncodingPlain:
+		buf := appendLength(nil, uint64(len(values)))
+
+		// Binary scores are saved since version 8
+		if e.version < 8 {
+			for _, v := range values {
+				buf = appendFloat(e.appendString(buf, v.Value), v.Score)
+			}
+
+			return typeZSet, buf, nil
+		}
+
+		for _, v := range values {
+			buf = appendBinaryDouble(e.appendString(buf, v.Value), v.Score)
+		}
+
+		return typeZSet2, buf, nil
+
+	case EncodingZipList, EncodingListPack:
+		flat := make([]string, 0, len(values)*2)
+
+		for _, v := range values {
+			flat = append(flat, v.Value, formatScore(v.Score))
+		}
+
+		if enc == EncodingZipList {
+			return typeZSetZipList, e.appendString(nil, string(encodeZipList(flat))), nil
+		}
+
+		return typeZSetListPack, e.appendString(nil, string(encodeListPack(flat))), nil
+	}
+
+	return 0, nil, UnsupportedEncodingError{DataType: "sorted set", Encoding: enc}
+}
+
+func (e *valueEncoder) encodeHash(data *HashData, enc Encoding) (byte, []byte, error) {
+	fields := make([]string, 0, len(data.Value))
+
+	for field := range data.Value {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	if len(data.FieldExpiry) > 0 {
+		return e.encodeHashWithExpiry(data, fields, enc)
+	}
+
+	switch enc {
+	case EncodingPlain:
+		buf := appendLength(nil, uint64(len(fields)))
+
+		for _, field := range fields {
+			buf = e.appendString(e.appendString(buf, field), data.Value[field])
+		}
+
+		return typeHash, buf, nil
+
+	case EncodingZipMap:
+		values := make([]HashValue, len(fields))
+
+		for i, field := range fields {
+			values[i] = HashValue{Index: field, Value: data.Value[field]}
+		}
+
+		return typeHashZipMap, e.appendString(nil, string(encodeZipMap(values))), nil
+
+	case EncodingZipList, EncodingListPack:
+		flat := make([]string, 0, len(fields)*2)
+
+		for _, field := range fields {
+			flat = append(flat, field, data.Value[field])
+		}
+
+		if enc == EncodingZipList {
+			return typeHashZipList, e.appendString(nil, string(encodeZipList(flat))), nil
+		}
+
+		return typeHashListPack, e.appendString(nil, string(encodeListPack(flat))), nil
+	}
+
+	return 0, nil, UnsupportedEncodingError{DataType: "hash", Encoding: enc}
+}
+
+func (e *valueEncoder) encodeHashWithExpiry(data *HashData, fields []string, enc Encoding) (byte, []byte, error) {
+	var minExpiry int64 = math.MaxInt64
+
+	for _, expiry := range data.FieldExpiry {
+		if ms := timeToMilliseconds(expiry); ms < minExpiry {
+			minExpiry = ms
+		}
+	}
+
+	buf := appendUint64(nil, uint64(minExpiry))
+
+	switch enc {
+	case EncodingPlain:
+		buf = appendLength(buf, uint64(len(fields)))
+
+		for _, field := range fields {
+			var ttl uint64 = hashNoTTL
+
+			// TTLs are saved relative to the minimum expiry
+			if expiry, ok := data.FieldExpiry[field]; ok {
+				ttl = uint64(timeToMilliseconds(expiry)-minExpiry) + 1
+			}
+
+			buf = appendLength(buf, ttl)
+			buf = e.appendString(e.appendString(buf, field), data.Value[field])
+		}
+
+		return typeHashMetadata, buf, nil
+
+	case EncodingListPack:
+		flat := make([]string, 0, len(fields)*3)
+
+		for _, field := range fields {
+			var ttl int64 = hashNoTTL
+
+			if expiry, ok := data.FieldExpiry[field]; ok {
+				ttl = timeToMilliseconds(expiry)
+			}
+
+			flat = append(flat, field, data.Value[field], strconv.FormatInt(ttl, 10))
+		}
+
+		return typeHashListPackEx, e.appendString(buf, string(encodeListPack(flat))), nil
+	}
+
+	return 0, nil, UnsupportedEncodingError{DataType: "hash with field expiry", Encoding: enc}
+}
+
+func (e *valueEncoder) streamType() byte {
+	switch {
+	case e.version >= 11:
+		return typeStreamListPacks3
+	case e.version >= 10:
+		return typeStreamListPacks2
+	}
+
+	return typeStreamListPacks
+}
+
+func (e *valueEncoder) encodeStream(data *StreamData) (byte, []byte, error) {
+	dataType := e.streamType()
+	nodes := splitStreamValues(data.Value, streamNodeEntries)
+	buf := appendLength(nil, uint64(len(nodes)))
+
+	for _, node := range nodes {
+		masterID := node[0].ID
+		buf = e.appendString(buf, string(appendStreamID(nil, masterID)))
+		buf = e.appendString(buf, string(encodeListPack(streamNodeValues(node))))
+	}
+
+	buf = appendLength(buf, uint64(data.Length))
+	buf = appendStreamIDLength(buf, data.LastID)
+
+	if dataType != typeStreamListPacks {
+		buf = appendStreamIDLength(buf, data.FirstID)
+		buf = appendStreamIDLength(buf, data.MaxDeletedID)
+		buf = appendLength(buf, uint64(data.EntriesAdded))
+	}
+
+	buf = appendLength(buf, uint64(len(data.Groups)))
+
+	for _, group := range data.Groups {
+		buf = e.appendString(buf, group.Name)
+		buf = appendStreamIDLength(buf, group.LastDeliveredID)
+
+		if dataType != typeStreamListPacks {
+			buf = appendLength(buf, uint64(group.EntriesRead))
+		}
+
+		buf = appendLength(buf, uint64(len(group.Pending)))
+
+		for _, entry := range group.Pending {
+			buf = appendStreamID(buf, entry.ID)
+			buf = appendMillisecondsTime(buf, entry.DeliveryTime)
+			buf = appendLength(buf, uint64(entry.DeliveryCount))
+		}
+
+		buf = appendLength(buf, uint64(len(group.Consumers)))
+
+		for _, consumer := range group.Consumers {
+			buf = e.appendString(buf, consumer.Name)
+			buf = appendMillisecondsTime(buf, consumer.SeenTime)
+
+			if dataType == typeStreamListPacks3 {
+				activeTime := consumer.SeenTime
+
+				if consumer.ActiveTime != nil {
+					activeTime = *consumer.ActiveTime
+				}
+
+				buf = appendMillisecondsTime(buf, activeTime)
+			}
+
+			buf = appendLength(buf, uint64(len(consumer.Pending)))
+
+			for _, entry := range consumer.Pending {
+				buf = appendStreamID(buf, entry.ID)
+			}
+		}
+	}
+
+	return dataType, buf, nil
+}
+
+// streamNodeValues returns listpack values of a stream node. Fields of the
+// first entry are saved in the master entry, and entries with the same fields
+// only save values.
+func streamNodeValues(node []StreamValue) []string {
+	master := node[0]
+	values := []string{
+		// count, deleted and the number of master fields
+		strconv.Itoa(len(node)), "0", strconv.Itoa(len(master.Fields)),
+	}
+
+	for _, field := range master.Fields {
+		values = append(values, field.Field)
+	}
+
+	// The master entry is terminated by a zero
+	values = append(values, "0")
+
+	for _, entry := range node {
+		sameFields := streamSameFields(master.Fields, entry.Fields)
+		flags := 0
+
+		if sameFields {
+			flags = streamItemFlagSameFields
+		}
+
+		values = append(values,
+			strconv.Itoa(flags),
+			strconv.FormatInt(int64(entry.ID.Millis-master.ID.Millis), 10),
+			strconv.FormatInt(int64(entry.ID.Sequence-master.ID.Sequence), 10),
+		)
+
+		// lp-count is the number of values of the entry without itself
+		lpCount := len(entry.Fields) + 3
+
+		if !sameFields {
+			values = append(values, strconv.Itoa(len(entry.Fields)))
+			lpCount += len(entry.Fields) + 1
+		}
+
+		for _, field := range entry.Fields {
+			if !sameFields {
+				values = append(values, field.Field)
+			}
+
+			values = append(values, field.Value)
+		}
+
+		values = append(values, strconv.Itoa(lpCount))
+	}
+
+	return values
+}
+
+func streamSameFields(master, fields []StreamField) bool {
+	if len(master) != len(fields) {
+		return false
+	}
+
+	for i := range master {
+		if master[i].Field != fields[i].Field {
+			return false
+		}
+	}
+
+	return true
+}
+
+func splitStreamValues(values []StreamValue, size int) [][]StreamValue {
+	var nodes [][]StreamValue
+
+	for len(values) > size {
+		nodes = append(nodes, values[:size])
+		values = values[size:]
+	}
+
+	if len(values) > 0 {
+		nodes = append(nodes, values)
+	}
+
+	return nodes
+}
+
+func appendStreamID(buf []byte, id StreamID) []byte {
+	return appendUint64BE(appendUint64BE(buf, id.Millis), id.Sequence)
+}
+
+func appendStreamIDLength(buf []byte, id StreamID) []byte {
+	return appendLength(appendLength(buf, id.Millis), id.Sequence)
+}
+
+func (e *valueEncoder) encodeModule(data *ModuleData) (byte, []byte, error) {
+	id, err := EncodeModuleID(data.ModuleName, data.EncodingVersion)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	buf, err := e.appendModuleValues(appendLength(nil, id), data.Values)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return typeModule2, buf, nil
+}
+
+// appendModuleValues appends values with opcodes and the EOF opcode.
+func (e *valueEncoder) appendModuleValues(buf []byte, values []ModuleValue) ([]byte, error) {
+	for _, value := range values {
+		buf = appendLength(buf, uint64(value.Type))
+
+		switch value.Type {
+		case ModuleValueSInt, ModuleValueUInt:
+			v, err := convert.Int64(value.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			buf = appendLength(buf, uint64(v))
+
+		case ModuleValueFloat:
+			v, err := convert.Float64(value.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			buf = appendUint32(buf, math.Float32bits(float32(v)))
+
+		case ModuleValueDouble:
+			v, err := convert.Float64(value.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			buf = appendBinaryDouble(buf, v)
+
+		case ModuleValueString:
+			v, err := convert.String(value.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			buf = e.appendString(buf, v)
+
+		default:
+			return nil, ModuleOpcodeError{Actual: int(value.Type), Expected: rdbModuleOpcodeEOF}
+		}
+	}
+
+	return appendLength(buf, rdbModuleOpcodeEOF), nil
+}
+
+func (e *valueEncoder) appendStrings(buf []byte, values []string) []byte {
+	for _, value := range values {
+		buf = e.appendString(buf, value)
+	}
+
+	return buf
+}
+
+func splitValues(values []string, size int) [][]string {
+	var nodes [][]string
+
+	for len(values) > size {
+		nodes = append(nodes, values[:size])
+		values = values[size:]
+	}
+
+	if len(values) > 0 {
+		nodes = append(nodes, values)
+	}
+
+	return nodes
+}
+
+// formatScore formats a score of a sorted set like Redis does when it is
+// saved in a ziplist or a listpack.
+func formatScore(score float64) string {
+	switch {
+	case math.IsNaN(score):
+		return "nan"
+	case math.IsInf(score, 1):
+		return "inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+
+	return strconv.FormatFloat(score, 'g', 17, 64)
+}
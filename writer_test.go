@@ -0,0 +1,326 @@
+package rdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type writerProfile struct {
+	Compress  bool
+	List      Encoding
+	Set       Encoding
+	SortedSet Encoding
+	Hash      Encoding
+	Version   int
+}
+
+// forVersion replaces encodings which don't exist in the RDB version with the
+// ones used by older versions of Redis.
+func (w writerProfile) forVersion(version int) writerProfile {
+	w.Version = version
+
+	if version < 10 {
+		if w.List == EncodingListPack {
+			w.List = EncodingQuickList
+		}
+
+		if w.SortedSet == EncodingListPack {
+			w.SortedSet = EncodingZipList
+		}
+
+		if w.Hash == EncodingListPack {
+			w.Hash = EncodingZipList
+		}
+	}
+
+	if version < 7 && w.List == EncodingQuickList {
+		w.List = EncodingZipList
+	}
+
+	if version < 11 && w.Set == EncodingListPack {
+		w.Set = EncodingIntSet
+	}
+
+	return w
+}
+
+func (w writerProfile) setEncoding(data *SetData) Encoding {
+	if w.Set != EncodingIntSet {
+		return w.Set
+	}
+
+	for _, value := range data.Value {
+		if _, ok := parseStringInteger(value); !ok {
+			if w.Version < 11 {
+				return EncodingPlain
+			}
+
+			return EncodingListPack
+		}
+	}
+
+	return EncodingIntSet
+}
+
+func (w writerProfile) hashEncoding(data *HashData) Encoding {
+	if len(data.FieldExpiry) > 0 && w.Hash != EncodingListPack {
+		return EncodingPlain
+	}
+
+	return w.Hash
+}
+
+var _ = Describe("Writer", func() {
+	// Values of the legacy module type can't be written, and pre-GA functions
+	// are written as FUNCTION2.
+	excludedFixtures := map[string]bool{
+		"redis_40_with_legacy_module":         true,
+		"redis_40_with_unknown_legacy_module": true,
+		"redis_70_with_functions_pre_ga":      true,
+	}
+
+	profiles := map[string]writerProfile{
+		"plain": {},
+		"ziplist": {
+			Compress:  true,
+			List:      EncodingZipList,
+			Set:       EncodingIntSet,
+			SortedSet: EncodingZipList,
+			Hash:      EncodingZipList,
+		},
+		"quicklist": {
+			List:      EncodingQuickList,
+			Set:       EncodingIntSet,
+			SortedSet: EncodingPlain,
+			Hash:      EncodingZipMap,
+		},
+		"listpack": {
+			Compress:  true,
+			List:      EncodingListPack,
+			Set:       EncodingListPack,
+			SortedSet: EncodingListPack,
+			Hash:      EncodingListPack,
+		},
+	}
+
+	// readAll returns all events except heads and entries of collections.
+	// Module values are returned as ModuleData.
+	readAll := func(r io.Reader) []interface{} {
+		var result []interface{}
+
+		parser := NewParser(r)
+		parser.modules = map[string]ModuleDecoder{}
+
+		for {
+			data, err := parser.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+
+			switch v := data.(type) {
+			case *ListHead, *ListEntry, *SetHead, *SetEntry, *SortedSetHead, *SortedSetEntry,
+				*HashHead, *HashEntry, *StreamHead, *StreamEntry:
+				continue
+
+			case *SetData:
+				sort.Strings(v.Value)
+
+			case *DatabaseSize:
+				// Databases are selected before their sizes
+				result = append(result, parser.db)
+			}
+
+			result = append(result, data)
+		}
+
+		return result
+	}
+
+	writeAll := func(events []interface{}, profile writerProfile) []byte {
+		var (
+			buf    bytes.Buffer
+			writer *Writer
+		)
+
+		for _, event := range events {
+			var err error
+
+			switch v := event.(type) {
+			case *Header:
+				profile = profile.forVersion(v.Version)
+				writer = NewWriter(&buf, v.Version)
+				writer.Compress = profile.Compress
+				err = writer.WriteHeader()
+			case int:
+				err = writer.WriteSelectDB(v)
+			case *Aux:
+				err = writer.WriteAux(v)
+			case *ModuleAux:
+				err = writer.WriteModuleAux(v)
+			case *FunctionLibrary:
+				err = writer.WriteFunction(v)
+			case *DatabaseSize:
+				err = writer.WriteDatabaseSize(v)
+			case *SlotInfo:
+				err = writer.WriteSlotInfo(v)
+			case *StringData:
+				err = writer.WriteString(v)
+			case *ListData:
+				err = writer.WriteList(v, profile.List)
+			case *SetData:
+				err = writer.WriteSet(v, profile.setEncoding(v))
+			case *SortedSetData:
+				err = writer.WriteSortedSet(v, profile.SortedSet)
+			case *HashData:
+				err = writer.WriteHash(v, profile.hashEncoding(v))
+			case *StreamData:
+				err = writer.WriteStream(v)
+			case *ModuleData:
+				err = writer.WriteModule(v)
+			default:
+				Fail(fmt.Sprintf("unexpected event %T", event))
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(writer.Close()).To(Succeed())
+
+		return buf.Bytes()
+	}
+
+	paths, err := filepath.Glob("fixtures/*.rdb")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".rdb")
+
+		if excludedFixtures[name] {
+			continue
+		}
+
+		for profileName, profile := range profiles {
+			path, profile := path, profile
+
+			It(fmt.Sprintf("should round-trip %s as %s", name, profileName), func() {
+				file, err := os.Open(path)
+				Expect(err).NotTo(HaveOccurred())
+				defer file.Close()
+
+				expected := readAll(file)
+				actual := readAll(bytes.NewReader(writeAll(expected, profile)))
+				Expect(actual).To(Equal(expected))
+			})
+		}
+	}
+
+	It("should write integer and compressed strings", func() {
+		var buf bytes.Buffer
+
+		writer := NewWriter(&buf, 9)
+		writer.Compress = true
+		Expect(writer.WriteHeader()).To(Succeed())
+		Expect(writer.WriteString(&StringData{
+			DataKey: DataKey{Key: "a"},
+			Value:   "-12345",
+		})).To(Succeed())
+		Expect(writer.WriteString(&StringData{
+			DataKey: DataKey{Key: "b"},
+			Value:   strings.Repeat("a", 100),
+		})).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+
+		Expect(buf.Bytes()).To(ContainSubstring("\x00\x01a\xc1\xc7\xcf"))
+		Expect(buf.Len()).To(BeNumerically("<", 100))
+	})
+
+	It("should not write checksum before version 5", func() {
+		var buf bytes.Buffer
+
+		writer := NewWriter(&buf, 4)
+		Expect(writer.WriteHeader()).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+		Expect(buf.String()).To(Equal("REDIS0004\xff"))
+	})
+
+	It("should return UnsupportedVersionError", func() {
+		writer := NewWriter(&bytes.Buffer{}, maxVersion+1)
+		Expect(writer.WriteHeader()).To(Equal(UnsupportedVersionError{Version: maxVersion + 1}))
+	})
+
+	It("should return UnsupportedOpCodeError when idle is not supported", func() {
+		writer := NewWriter(&bytes.Buffer{}, 8)
+		Expect(writer.WriteString(&StringData{
+			DataKey: DataKey{Key: "a", Idle: durationPtr(time.Second)},
+		})).To(Equal(UnsupportedOpCodeError{OpCode: opCodeIdle, Version: 8}))
+	})
+
+	It("should return IntSetValueError", func() {
+		writer := NewWriter(&bytes.Buffer{}, 9)
+		err := writer.WriteSet(&SetData{
+			DataKey: DataKey{Key: "a"},
+			Value:   []string{"1", "a"},
+		}, EncodingIntSet)
+		Expect(errors.Is(err, IntSetValueError{Value: "a"})).To(BeTrue())
+	})
+
+	It("should return UnsupportedEncodingError when listpack sets are not supported", func() {
+		writer := NewWriter(&bytes.Buffer{}, 9)
+		err := writer.WriteSet(&SetData{
+			DataKey: DataKey{Key: "a"},
+			Value:   []string{"a"},
+		}, EncodingListPack)
+		Expect(errors.Is(err, UnsupportedEncodingError{DataType: "set", Encoding: EncodingListPack, Version: 9})).To(BeTrue())
+	})
+
+	It("should return UnsupportedEncodingError when quicklists are not supported", func() {
+		writer := NewWriter(&bytes.Buffer{}, 6)
+		err := writer.WriteList(&ListData{
+			DataKey: DataKey{Key: "a"},
+			Value:   []string{"a"},
+		}, EncodingQuickList)
+		Expect(errors.Is(err, UnsupportedEncodingError{DataType: "list", Encoding: EncodingQuickList, Version: 6})).To(BeTrue())
+	})
+
+	It("should return UnsupportedEncodingError when hash field expiry is not supported", func() {
+		writer := NewWriter(&bytes.Buffer{}, 11)
+		err := writer.WriteHash(&HashData{
+			DataKey:     DataKey{Key: "a"},
+			Value:       map[string]string{"a": "b"},
+			FieldExpiry: map[string]time.Time{"a": time.Unix(1700000000, 0)},
+		}, EncodingListPack)
+		Expect(errors.Is(err, UnsupportedEncodingError{
+			DataType: "hash with field expiry",
+			Encoding: EncodingListPack,
+			Version:  11,
+		})).To(BeTrue())
+	})
+
+	It("should return UnsupportedDataTypeError when streams are not supported", func() {
+		writer := NewWriter(&bytes.Buffer{}, 8)
+		err := writer.WriteStream(&StreamData{DataKey: DataKey{Key: "a"}})
+		Expect(errors.Is(err, UnsupportedDataTypeError{DataType: typeStreamListPacks})).To(BeTrue())
+	})
+
+	It("should return UnsupportedEncodingError", func() {
+		writer := NewWriter(&bytes.Buffer{}, 9)
+		err := writer.WriteList(&ListData{
+			DataKey: DataKey{Key: "a"},
+		}, EncodingIntSet)
+		Expect(errors.Is(err, UnsupportedEncodingError{DataType: "list", Encoding: EncodingIntSet})).To(BeTrue())
+	})
+})
@@ -1,8 +1,10 @@
 package rdb
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 )
 
 type zipListIterator struct {
@@ -175,3 +177,80 @@ func readZipListEntry(r byteReader) (interface{}, error) {
 
 	return nil, ZipListHeaderError{Header: header}
 }
+
+// encodeZipList returns a ziplist containing values. Values are saved as
+// integers when possible.
+func encodeZipList(values []string) []byte {
+	// zlbytes, zltail and zllen are filled in later
+	buf := make([]byte, 10, 11)
+	tail := len(buf)
+	prevLen := 0
+
+	for _, value := range values {
+		start := len(buf)
+		tail = start
+
+		if prevLen < 254 {
+			buf = append(buf, byte(prevLen))
+		} else {
+			buf = appendUint32(append(buf, 254), uint32(prevLen))
+		}
+
+		if v, ok := parseStringInteger(value); ok {
+			buf = appendZipListInteger(buf, v)
+		} else {
+			buf = appendZipListString(buf, value)
+		}
+
+		prevLen = len(buf) - start
+	}
+
+	buf = append(buf, 255)
+
+	length := len(values)
+	if length > math.MaxUint16 {
+		length = math.MaxUint16
+	}
+
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(tail))
+	binary.LittleEndian.PutUint16(buf[8:], uint16(length))
+
+	return buf
+}
+
+func appendZipListString(buf []byte, value string) []byte {
+	length := len(value)
+
+	switch {
+	case length < 1<<6:
+		buf = append(buf, byte(length))
+	case length < 1<<14:
+		buf = append(buf, 0x40|byte(length>>8), byte(length))
+	default:
+		buf = append(buf, 0x80, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+	}
+
+	return append(buf, value...)
+}
+
+func appendZipListInteger(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v <= 12:
+		return append(buf, 241+byte(v))
+
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(buf, 254, byte(v))
+
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return appendUint16(append(buf, 0xc0), uint16(v))
+
+	case v >= -(1<<23) && v < 1<<23:
+		return append(buf, 240, byte(v), byte(v>>8), byte(v>>16))
+
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return appendUint32(append(buf, 0xd0), uint32(v))
+	}
+
+	return appendUint64(append(buf, 0xe0), uint64(v))
+}
@@ -121,3 +121,35 @@ func (z *zipMapIterator) readLength() (int, error) {
 
 	return 0, io.EOF
 }
+
+// encodeZipMap returns a zipmap containing values.
+func encodeZipMap(values []HashValue) []byte {
+	length := len(values)
+	if length > 254 {
+		length = 254
+	}
+
+	buf := []byte{byte(length)}
+
+	for _, value := range values {
+		buf = appendZipMapString(buf, value.Index)
+		buf = appendZipMapLength(buf, len(value.Value))
+		// free
+		buf = append(buf, 0)
+		buf = append(buf, value.Value...)
+	}
+
+	return append(buf, 255)
+}
+
+func appendZipMapLength(buf []byte, length int) []byte {
+	if length < 254 {
+		return append(buf, byte(length))
+	}
+
+	return appendUint32(append(buf, 254), uint32(length))
+}
+
+func appendZipMapString(buf []byte, value string) []byte {
+	return append(appendZipMapLength(buf, len(value)), value...)
+}